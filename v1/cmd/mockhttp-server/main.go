@@ -0,0 +1,88 @@
+// Command mockhttp-server serves the stubs defined in a YAML or JSON config
+// file, reloading them whenever the file changes.
+//
+//	mockhttp-server -config stubs.yaml -addr :8080
+//
+// With -proxy, requests that match no stub are forwarded to the given
+// upstream instead of getting a 404:
+//
+//	mockhttp-server -config stubs.yaml -proxy http://localhost:9000
+//
+// Stubs from the config file are defaults: they survive DELETE /__admin/reset
+// and are overridden by stubs registered at runtime through the admin API,
+// see mockhttp.NewAdminHandler for the full list of endpoints.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+)
+
+func main() {
+	config := flag.String("config", "", "path to a YAML or JSON stub config file")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	proxy := flag.String("proxy", "", "upstream URL that unmatched requests are forwarded to")
+	interval := flag.Duration("reload-interval", time.Second, "how often to check the config file for changes")
+	flag.Parse()
+
+	if *config == "" {
+		log.Fatal("-config is required")
+	}
+
+	stubs := mockhttp.NewStubHandler().WithLogger(log.Default())
+	if *proxy != "" {
+		upstream, err := url.Parse(*proxy)
+		if err != nil {
+			log.Fatalf("invalid -proxy: %v", err)
+		}
+		stubs.WithProxy(upstream)
+	}
+
+	modTime, err := load(stubs, *config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go watch(stubs, *config, modTime, *interval)
+
+	log.Printf("serving stubs from %s on %s", *config, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mockhttp.WithAdmin(stubs)))
+}
+
+func load(stubs *mockhttp.StubHandler, path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loaded, err := mockhttp.LoadStubs(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	stubs.SetDefaultStubs(loaded...)
+	log.Printf("loaded %d stub(s) from %s", len(loaded), path)
+	return info.ModTime(), nil
+}
+
+// watch polls the config file and reloads it when its modification time
+// changes. A config that fails to load leaves the previous stubs in place
+func watch(stubs *mockhttp.StubHandler, path string, modTime time.Time, interval time.Duration) {
+	for range time.Tick(interval) {
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Printf("unable to stat %s: %v", path, err)
+			continue
+		}
+		if info.ModTime().Equal(modTime) {
+			continue
+		}
+		modTime = info.ModTime()
+		if _, err := load(stubs, path); err != nil {
+			log.Printf("keeping previous stubs, unable to reload %s: %v", path, err)
+		}
+	}
+}
@@ -114,3 +114,73 @@ func handleRequestWithContext(w http.ResponseWriter, r *http.Request) {
 	}
 	response.SuccessWithBody(w, vals)
 }
+
+// Unexported key types, like the ones real middleware uses, can't collide
+// with keys from other packages
+type (
+	idKey   struct{}
+	cityKey struct{}
+)
+
+func TestTypedContextValues(t *testing.T) {
+	tests := []myTestStruct[contextStruct, mockhttp.ServerError]{
+		{
+			Name: "no_city_in_context",
+			Input: mockhttp.NewRequest("GET", "/", "").
+				// Add values with typed keys using these functions
+				WithContextFuncs(mockhttp.WithValue(idKey{}, 123)),
+			Error: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "expected a city of type string in context",
+				}).
+				WithValidationFunc(mockhttp.ValidateErrors),
+		},
+		{
+			Name: "success",
+			Input: mockhttp.NewRequest("GET", "/", "").
+				WithContextFuncs(
+					mockhttp.WithValue(idKey{}, 123),
+					mockhttp.WithValue(cityKey{}, "Dallas"),
+				),
+			Success: mockhttp.NewJSONResponse[contextStruct]().
+				WithSuccess(&contextStruct{
+					ID:   123,
+					City: "Dallas",
+				}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			handleRequestWithTypedContext(tt.Input.W, tt.Input.R)
+
+			if tt.Error != nil {
+				res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](tt.Input.Result())
+				assert.Nil(t, err)
+				assert.Nil(t, tt.Error.Validate(res))
+			}
+
+			if tt.Success != nil {
+				res, err := mockhttp.ToJSONResponse[contextStruct](tt.Input.Result())
+				assert.Nil(t, err)
+				assert.Nil(t, tt.Success.Validate(res))
+			}
+		})
+	}
+}
+
+// This handler reads the same values as handleRequestWithContext, but with
+// typed keys
+func handleRequestWithTypedContext(w http.ResponseWriter, r *http.Request) {
+	id, ok := r.Context().Value(idKey{}).(int)
+	if !ok {
+		response.Error(w, 400, "expected an id of type int in context", nil)
+		return
+	}
+	city, ok := r.Context().Value(cityKey{}).(string)
+	if !ok {
+		response.Error(w, 400, "expected a city of type string in context", nil)
+		return
+	}
+	response.SuccessWithBody(w, contextStruct{ID: id, City: city})
+}
@@ -55,6 +55,54 @@ func TestChiPathParams(t *testing.T) {
 	}
 }
 
+// The same cases can run against the handler with faked path params, or
+// through the real router with Serve, which also catches routing mistakes
+// like a wrong pattern or method
+func TestChiRouting(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/things/{id}", handleChiPathParams)
+	router.Get("/things/{id}/{name}", handleChiPathParams)
+
+	tests := []struct {
+		Name     string
+		Input    func() *mockhttp.Request
+		Expected *mockhttp.RawResponse
+	}{
+		{
+			Name: "id_param",
+			Input: func() *mockhttp.Request {
+				return mockhttp.NewRequest("GET", "/things/1", "").
+					WithPathParams(mockhttp.Chi, map[string]string{"id": "1"})
+			},
+			Expected: mockhttp.NewRawResponse().WithStatus(200).WithBody(`{"id":"1"}`),
+		},
+		{
+			Name: "multiple_params",
+			Input: func() *mockhttp.Request {
+				return mockhttp.NewRequest("GET", "/things/1/wax", "").
+					WithPathParams(mockhttp.Chi, map[string]string{"id": "1", "name": "wax"})
+			},
+			Expected: mockhttp.NewRawResponse().WithStatus(200).WithBody(`{"id":"1","name":"wax"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name+"/unit", func(t *testing.T) {
+			req := tt.Input()
+			handleChiPathParams(req.W, req.R)
+
+			res, err := mockhttp.ToResponse(req.Result())
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expected, res)
+		})
+		t.Run(tt.Name+"/routed", func(t *testing.T) {
+			res, err := mockhttp.ToResponse(tt.Input().Serve(router))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expected, res)
+		})
+	}
+}
+
 func handleChiPathParams(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if len(id) == 0 {
@@ -0,0 +1,109 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/sachsry/mockhttp/v1/response"
+)
+
+// AdminPrefix is the path prefix reserved for the admin API
+const AdminPrefix = "/__admin"
+
+// ScenarioStateBody is the payload of PUT /__admin/scenarios/{name}
+type ScenarioStateBody struct {
+	State string `json:"state"`
+}
+
+// NewAdminHandler exposes the state of a StubHandler over HTTP so that stubs
+// can be programmed from other processes and languages:
+//
+//	GET    /__admin/stubs             lists the stubs
+//	POST   /__admin/stubs             registers a stub, in the same JSON format as config files
+//	DELETE /__admin/stubs/{id}        removes a registered stub, or sends a 409 for a default stub
+//	GET    /__admin/requests          lists the recorded requests
+//	DELETE /__admin/requests          clears the recorded requests
+//	GET    /__admin/scenarios         lists the scenario states
+//	PUT    /__admin/scenarios/{name}  sets the state of a scenario
+//	DELETE /__admin/scenarios         moves every scenario back to ScenarioStarted
+//	DELETE /__admin/reset             clears registered stubs, requests and scenarios
+func NewAdminHandler(s *StubHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Route(AdminPrefix, func(r chi.Router) {
+		r.Get("/stubs", func(w http.ResponseWriter, r *http.Request) {
+			response.SuccessWithBody(w, s.Stubs())
+		})
+		r.Post("/stubs", func(w http.ResponseWriter, r *http.Request) {
+			var stub Stub
+			if err := json.NewDecoder(r.Body).Decode(&stub); err != nil {
+				response.Error(w, http.StatusBadRequest, "unable to decode stub", err)
+				return
+			}
+			if stub.Response == nil {
+				response.Error(w, http.StatusBadRequest, "stub has no response", nil)
+				return
+			}
+			if stub.Request == nil {
+				stub.Request = &RequestPattern{}
+			}
+			s.Register(&stub)
+			response.Created(w, AdminPrefix+"/stubs/"+stub.ID, stub)
+		})
+		r.Delete("/stubs/{id}", func(w http.ResponseWriter, r *http.Request) {
+			id := chi.URLParam(r, "id")
+			if !s.RemoveStub(id) {
+				if s.isDefault(id) {
+					response.Error(w, http.StatusConflict, "default stubs can't be removed", nil)
+					return
+				}
+				response.Error(w, http.StatusNotFound, "no stub with that id", nil)
+				return
+			}
+			response.Success(w)
+		})
+
+		r.Get("/requests", func(w http.ResponseWriter, r *http.Request) {
+			response.SuccessWithBody(w, s.Requests())
+		})
+		r.Delete("/requests", func(w http.ResponseWriter, r *http.Request) {
+			s.ResetRequests()
+			response.Success(w)
+		})
+
+		r.Get("/scenarios", func(w http.ResponseWriter, r *http.Request) {
+			response.SuccessWithBody(w, s.Scenarios())
+		})
+		r.Put("/scenarios/{name}", func(w http.ResponseWriter, r *http.Request) {
+			var body ScenarioStateBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				response.Error(w, http.StatusBadRequest, "unable to decode scenario state", err)
+				return
+			}
+			if body.State == "" {
+				response.Error(w, http.StatusBadRequest, "scenario state is required", nil)
+				return
+			}
+			s.SetScenarioState(chi.URLParam(r, "name"), body.State)
+			response.Success(w)
+		})
+		r.Delete("/scenarios", func(w http.ResponseWriter, r *http.Request) {
+			s.ResetScenarios()
+			response.Success(w)
+		})
+
+		r.Delete("/reset", func(w http.ResponseWriter, r *http.Request) {
+			s.Reset()
+			response.Success(w)
+		})
+	})
+	return r
+}
+
+// WithAdmin serves the admin API under AdminPrefix and the stubs everywhere else
+func WithAdmin(s *StubHandler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(AdminPrefix+"/", NewAdminHandler(s))
+	mux.Handle("/", s)
+	return mux
+}
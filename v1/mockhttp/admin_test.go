@@ -0,0 +1,179 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler_RegisterStub(t *testing.T) {
+	stubs := mockhttp.NewStubHandler()
+	admin := mockhttp.WithAdmin(stubs)
+
+	httpReq := mockhttp.NewRequest("POST", "/__admin/stubs", `{
+		"request": {"method": "GET", "path": "/users/{id}"},
+		"response": {"status": 200, "jsonBody": {"id": 1, "name": "wax"}}
+	}`)
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	created, err := mockhttp.ToJSONResponse[mockhttp.Stub](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, 201, created.Status())
+	assert.NotEmpty(t, created.Val.ID)
+	assert.Equal(t, "application/json", created.Header().Get("Content-Type"))
+	assert.Equal(t, "/__admin/stubs/"+created.Val.ID, created.Header().Get("Location"))
+
+	httpReq = mockhttp.NewRequest("GET", "/users/1", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	res, err := mockhttp.ToJSONResponse[user](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, "wax", res.Val.Name)
+
+	httpReq = mockhttp.NewRequest("DELETE", "/__admin/stubs/"+created.Val.ID, "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Equal(t, 200, httpReq.W.Code)
+	assert.Len(t, stubs.Stubs(), 0)
+
+	httpReq = mockhttp.NewRequest("DELETE", "/__admin/stubs/"+created.Val.ID, "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Equal(t, 404, httpReq.W.Code)
+}
+
+func TestAdminHandler_RegisterStubOverHTTP(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+
+	res, err := http.Post(server.URL()+"/__admin/stubs", "application/json",
+		strings.NewReader(`{"request": {"path": "/ping"}, "response": {"status": 204}}`))
+	assert.Nil(t, err)
+	created, err := mockhttp.ToJSONResponse[mockhttp.Stub](res)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, created.Status())
+	assert.Equal(t, "application/json", created.Header().Get("Content-Type"))
+	assert.Equal(t, "/ping", created.Val.Request.Path)
+}
+
+func TestAdminHandler_InvalidStub(t *testing.T) {
+	httpReq := mockhttp.NewRequest("POST", "/__admin/stubs", `{"request": {"path": "/"}}`)
+	mockhttp.WithAdmin(mockhttp.NewStubHandler()).ServeHTTP(httpReq.W, httpReq.R)
+
+	res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, 400, res.Status())
+	assert.Equal(t, "stub has no response", res.Val.DebugMessage)
+}
+
+func TestAdminHandler_Requests(t *testing.T) {
+	stubs := mockhttp.NewStubHandler()
+	stubs.Register(mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users")))
+	admin := mockhttp.WithAdmin(stubs)
+
+	httpReq := mockhttp.NewRequest("GET", "/users", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+
+	httpReq = mockhttp.NewRequest("GET", "/__admin/requests", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	res, err := mockhttp.ToJSONResponse[[]mockhttp.RecordedRequest](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Len(t, *res.Val, 1)
+	assert.Equal(t, "/users", (*res.Val)[0].Path)
+
+	httpReq = mockhttp.NewRequest("DELETE", "/__admin/requests", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Len(t, stubs.Requests(), 0)
+	assert.Len(t, stubs.Stubs(), 1)
+}
+
+func TestAdminHandler_ResetKeepsDefaults(t *testing.T) {
+	stubs := mockhttp.NewStubHandler()
+	stubs.SetDefaultStubs(mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users")).WillReturn(200, "default"))
+	stubs.Register(mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users")).WillReturn(200, "override"))
+	admin := mockhttp.WithAdmin(stubs)
+
+	httpReq := mockhttp.NewRequest("GET", "/users", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Equal(t, "override", httpReq.W.Body.String())
+
+	httpReq = mockhttp.NewRequest("DELETE", "/__admin/reset", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+
+	httpReq = mockhttp.NewRequest("GET", "/users", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Equal(t, "default", httpReq.W.Body.String())
+	assert.Len(t, stubs.Requests(), 1)
+}
+
+func TestAdminHandler_RemoveDefaultStub(t *testing.T) {
+	stubs := mockhttp.NewStubHandler()
+	stubs.SetDefaultStubs(mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users")).WillReturn(200, "default"))
+	admin := mockhttp.WithAdmin(stubs)
+
+	httpReq := mockhttp.NewRequest("GET", "/__admin/stubs", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	listed, err := mockhttp.ToJSONResponse[[]mockhttp.Stub](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Len(t, *listed.Val, 1)
+
+	httpReq = mockhttp.NewRequest("DELETE", "/__admin/stubs/"+(*listed.Val)[0].ID, "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, 409, res.Status())
+	assert.Equal(t, "default stubs can't be removed", res.Val.DebugMessage)
+	assert.Len(t, stubs.Stubs(), 1)
+}
+
+func TestStubHandler_Scenarios(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/order")).
+			InScenario("checkout", mockhttp.ScenarioStarted, "").
+			WillReturn(200, "pending"),
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/order/pay")).
+			InScenario("checkout", mockhttp.ScenarioStarted, "paid").
+			WillReturn(204, ""),
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/order")).
+			InScenario("checkout", "paid", "").
+			WillReturn(200, "paid"),
+	)
+
+	assert.Equal(t, "pending", get(t, server.URL()+"/order"))
+	post(t, server.URL()+"/order/pay", "")
+	assert.Equal(t, "paid", server.ScenarioState("checkout"))
+	assert.Equal(t, "paid", get(t, server.URL()+"/order"))
+
+	req, _ := http.NewRequest("DELETE", server.URL()+"/__admin/scenarios", nil)
+	_, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "pending", get(t, server.URL()+"/order"))
+}
+
+func TestAdminHandler_SetScenarioState(t *testing.T) {
+	stubs := mockhttp.NewStubHandler()
+	admin := mockhttp.WithAdmin(stubs)
+
+	httpReq := mockhttp.NewRequest("PUT", "/__admin/scenarios/checkout", `{"state":"paid"}`)
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Equal(t, 200, httpReq.W.Code)
+
+	httpReq = mockhttp.NewRequest("GET", "/__admin/scenarios", "")
+	admin.ServeHTTP(httpReq.W, httpReq.R)
+	res, err := mockhttp.ToJSONResponse[map[string]string](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"checkout": "paid"}, *res.Val)
+}
+
+func get(t *testing.T, url string) string {
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+	raw, err := mockhttp.ToResponse(res)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+	return raw.Body()
+}
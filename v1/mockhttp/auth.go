@@ -0,0 +1,264 @@
+package mockhttp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWT signing algorithms supported by SigningKey
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// WithBasicAuth sets the Authorization header to HTTP Basic credentials
+func (r *Request) WithBasicAuth(username, password string) *Request {
+	r.R.SetBasicAuth(username, password)
+	return r
+}
+
+// WithBearer sets the Authorization header to a bearer token
+func (r *Request) WithBearer(token string) *Request {
+	return r.SetHeader("Authorization", "Bearer "+token)
+}
+
+// WithAPIKey sets an API key header, such as X-Api-Key
+func (r *Request) WithAPIKey(header, key string) *Request {
+	return r.SetHeader(header, key)
+}
+
+// WithJWT signs the claims with key and sends the token as a bearer token.
+// It panics if the claims can't be marshaled to JSON
+func (r *Request) WithJWT(claims Claims, key *SigningKey) *Request {
+	token, err := key.Sign(claims)
+	if err != nil {
+		panic(fmt.Sprintf("unable to sign JWT: %v", err))
+	}
+	return r.WithBearer(token)
+}
+
+// Claims is the payload of a JWT
+type Claims map[string]interface{}
+
+// NewClaims returns claims for subject that were issued now and expire in an hour
+func NewClaims(subject string) Claims {
+	now := time.Now()
+	return Claims{
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// WithIssuer sets the iss claim
+func (c Claims) WithIssuer(issuer string) Claims {
+	c["iss"] = issuer
+	return c
+}
+
+// WithAudience sets the aud claim. A single audience is sent as a string
+func (c Claims) WithAudience(audience ...string) Claims {
+	if len(audience) == 1 {
+		c["aud"] = audience[0]
+	} else {
+		c["aud"] = audience
+	}
+	return c
+}
+
+// WithExpiry sets the exp claim to d from now. A negative d mints an expired token
+func (c Claims) WithExpiry(d time.Duration) Claims {
+	c["exp"] = time.Now().Add(d).Unix()
+	return c
+}
+
+// With sets any other claim
+func (c Claims) With(key string, val interface{}) Claims {
+	c[key] = val
+	return c
+}
+
+// SigningKey is a key for minting test JWTs. RSA and ECDSA keys are generated
+// locally, so tokens are real but only trusted by tests that fetch the JWKS
+type SigningKey struct {
+	// ID is sent as the kid header and in the JWKS
+	ID  string
+	Alg string
+
+	secret  []byte
+	private crypto.Signer
+}
+
+// NewHS256Key returns a key that signs with HMAC SHA-256 using secret
+func NewHS256Key(secret []byte) *SigningKey {
+	return &SigningKey{Alg: HS256, secret: secret}
+}
+
+// NewRS256Key generates a 2048 bit RSA key
+func NewRS256Key() *SigningKey {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("unable to generate RSA key: %v", err))
+	}
+	return newAsymmetricKey(RS256, private)
+}
+
+// NewES256Key generates a P-256 ECDSA key
+func NewES256Key() *SigningKey {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("unable to generate ECDSA key: %v", err))
+	}
+	return newAsymmetricKey(ES256, private)
+}
+
+func newAsymmetricKey(alg string, private crypto.Signer) *SigningKey {
+	der, _ := x509.MarshalPKIXPublicKey(private.Public())
+	sum := sha256.Sum256(der)
+	return &SigningKey{
+		ID:      base64.RawURLEncoding.EncodeToString(sum[:8]),
+		Alg:     alg,
+		private: private,
+	}
+}
+
+// Public returns the public key, or nil for HS256 keys
+func (k *SigningKey) Public() crypto.PublicKey {
+	if k.private == nil {
+		return nil
+	}
+	return k.private.Public()
+}
+
+// Sign returns a compact JWT with claims as its payload
+func (k *SigningKey) Sign(claims Claims) (string, error) {
+	header := map[string]string{"alg": k.Alg, "typ": "JWT"}
+	if k.ID != "" {
+		header["kid"] = k.ID
+	}
+	rawHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	rawClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(rawClaims)
+
+	sig, err := k.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func (k *SigningKey) sign(input []byte) ([]byte, error) {
+	switch k.Alg {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, k.private.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case ES256:
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, k.private.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed width R || S encoding rather than ASN.1
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	}
+	return nil, fmt.Errorf("unsupported JWT algorithm: %s", k.Alg)
+}
+
+// JWK is the public half of a SigningKey as a JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the body of a JWKS endpoint
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKSet returns the public keys of the RSA and ECDSA keys. HS256 keys are
+// secret and left out
+func NewJWKSet(keys ...*SigningKey) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range keys {
+		jwk := JWK{Kid: k.ID, Alg: k.Alg, Use: "sig"}
+		switch pub := k.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			x, y := make([]byte, 32), make([]byte, 32)
+			pub.X.FillBytes(x)
+			pub.Y.FillBytes(y)
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(x)
+			jwk.Y = base64.RawURLEncoding.EncodeToString(y)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// NewJWKSStub returns a stub that serves the keys as a JWKS on GET path, so
+// token validation middleware can fetch them from a StubServer
+func NewJWKSStub(path string, keys ...*SigningKey) *Stub {
+	return NewStub(NewRequestPattern(http.MethodGet, path)).
+		WillReturnJSON(http.StatusOK, NewJWKSet(keys...)).
+		WithResponseHeader("Cache-Control", "max-age=300")
+}
+
+// ParseJWT decodes the claims of a token without verifying its signature
+func ParseJWT(token string) (Claims, error) {
+	parts := strings.Split(strings.TrimPrefix(token, "Bearer "), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected a JWT with 3 parts, but got %d", len(parts))
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims Claims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
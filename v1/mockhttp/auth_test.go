@@ -0,0 +1,122 @@
+package mockhttp_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest_WithBasicAuth(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "").WithBasicAuth("wax", "secret")
+
+	username, password, ok := httpReq.R.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "wax", username)
+	assert.Equal(t, "secret", password)
+}
+
+func TestRequest_WithBearerAndAPIKey(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "").WithBearer("abc").WithAPIKey("X-Api-Key", "123")
+
+	assert.Equal(t, "Bearer abc", httpReq.R.Header.Get("Authorization"))
+	assert.Equal(t, "123", httpReq.R.Header.Get("X-Api-Key"))
+}
+
+func TestRequest_WithJWT_HS256(t *testing.T) {
+	secret := []byte("test-secret")
+	claims := mockhttp.NewClaims("user-1").WithAudience("api").WithExpiry(-time.Minute)
+
+	httpReq := mockhttp.NewRequest("GET", "/", "").WithJWT(claims, mockhttp.NewHS256Key(secret))
+
+	token := strings.TrimPrefix(httpReq.R.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), parts[2])
+
+	parsed, err := mockhttp.ParseJWT(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "user-1", parsed["sub"])
+	assert.Equal(t, "api", parsed["aud"])
+	assert.Less(t, parsed["exp"].(float64), float64(time.Now().Unix()))
+}
+
+func TestRequest_WithJWT_JWKS(t *testing.T) {
+	rsaKey := mockhttp.NewRS256Key()
+	ecKey := mockhttp.NewES256Key()
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(mockhttp.NewJWKSStub("/.well-known/jwks.json", rsaKey, ecKey, mockhttp.NewHS256Key([]byte("secret"))))
+
+	res, err := http.Get(server.URL() + "/.well-known/jwks.json")
+	assert.Nil(t, err)
+	jwks, err := mockhttp.ToJSONResponse[mockhttp.JWKSet](res)
+	assert.Nil(t, err)
+	assert.Len(t, jwks.Val.Keys, 2)
+
+	for _, key := range []*mockhttp.SigningKey{rsaKey, ecKey} {
+		t.Run(key.Alg, func(t *testing.T) {
+			httpReq := mockhttp.NewRequest("GET", "/", "").
+				WithJWT(mockhttp.NewClaims("user-1").WithIssuer("test"), key)
+
+			token := strings.TrimPrefix(httpReq.R.Header.Get("Authorization"), "Bearer ")
+			assert.Nil(t, verifyJWT(token, jwks.Val))
+
+			tampered := strings.Split(token, ".")
+			tampered[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+			assert.NotNil(t, verifyJWT(strings.Join(tampered, "."), jwks.Val))
+		})
+	}
+}
+
+// verifyJWT checks a token against a JWKS the way validation middleware would
+func verifyJWT(token string, jwks *mockhttp.JWKSet) error {
+	parts := strings.Split(token, ".")
+	rawHeader, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return err
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Kid != header.Kid {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			pub := &rsa.PublicKey{N: decodeBigInt(jwk.N), E: int(decodeBigInt(jwk.E).Int64())}
+			return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig)
+		case "EC":
+			pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: decodeBigInt(jwk.X), Y: decodeBigInt(jwk.Y)}
+			if !ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+				return errors.New("invalid signature")
+			}
+			return nil
+		}
+	}
+	return errors.New("no key for kid " + header.Kid)
+}
+
+func decodeBigInt(s string) *big.Int {
+	raw, _ := base64.RawURLEncoding.DecodeString(s)
+	return new(big.Int).SetBytes(raw)
+}
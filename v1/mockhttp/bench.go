@@ -0,0 +1,181 @@
+package mockhttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Benchmark replays requests against a handler inside a testing.B without
+// allocating a new recorder or body per iteration, so the reported allocations
+// are the handler's own
+type Benchmark struct {
+	handler     http.HandlerFunc
+	requests    []*Request
+	percentiles []float64
+	// err records a misused builder, and fails Run
+	err error
+}
+
+// BenchResult is what a Benchmark measured
+type BenchResult struct {
+	N int
+	// Percentiles maps each requested percentile to its latency
+	Percentiles map[float64]time.Duration
+}
+
+// Bench benchmarks handler with req, reporting allocations and bytes per op
+func Bench(b *testing.B, handler http.HandlerFunc, req *Request) *BenchResult {
+	return NewBenchmark(handler).WithRequests(req).Run(b)
+}
+
+// NewBenchmark returns a Benchmark for handler
+func NewBenchmark(handler http.HandlerFunc) *Benchmark {
+	return &Benchmark{handler: handler}
+}
+
+// WithRequests sets the requests to replay. Iterations cycle through them in
+// order, which varies the input across a run
+func (bm *Benchmark) WithRequests(reqs ...*Request) *Benchmark {
+	bm.requests = append(bm.requests, reqs...)
+	return bm
+}
+
+// WithPercentiles times every iteration and reports each percentile, such as
+// 50 or 99, as a p50-ns style metric. Timing adds a little overhead per op.
+// Run fails if a percentile is outside 0 to 100
+func (bm *Benchmark) WithPercentiles(percentiles ...float64) *Benchmark {
+	for _, p := range percentiles {
+		if (p < 0 || p > 100 || math.IsNaN(p)) && bm.err == nil {
+			bm.err = fmt.Errorf("expected percentiles between 0 and 100, but got %g", p)
+		}
+	}
+	bm.percentiles = append(bm.percentiles, percentiles...)
+	return bm
+}
+
+// Run runs the benchmark. Each request is served once before timing starts,
+// and b fails if that returns a 5xx. The handler must not rely on changes it
+// makes to the request, since the same *http.Request is replayed
+func (bm *Benchmark) Run(b *testing.B) *BenchResult {
+	b.Helper()
+	if bm.err != nil {
+		b.Fatal(bm.err)
+	}
+	if len(bm.requests) == 0 {
+		b.Fatal("expected at least one request to benchmark, but got none")
+	}
+
+	replays := make([]*replay, len(bm.requests))
+	for i, req := range bm.requests {
+		var body []byte
+		if req.R.Body != nil {
+			body, _ = io.ReadAll(req.R.Body)
+		}
+		replays[i] = &replay{r: req.R, body: body, reader: &rewindBody{}}
+	}
+
+	w := &benchWriter{header: http.Header{}}
+	for i, rp := range replays {
+		rp.reset()
+		w.reset()
+		bm.handler(w, rp.r)
+		if w.status >= 500 {
+			b.Fatalf("expected request %d to succeed, but got status %d: %s", i, w.status, w.body)
+		}
+	}
+
+	var durations []time.Duration
+	if len(bm.percentiles) > 0 {
+		durations = make([]time.Duration, 0, b.N)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rp := replays[i%len(replays)]
+		rp.reset()
+		w.reset()
+		if durations == nil {
+			bm.handler(w, rp.r)
+			continue
+		}
+		start := time.Now()
+		bm.handler(w, rp.r)
+		durations = append(durations, time.Since(start))
+	}
+	b.StopTimer()
+
+	result := &BenchResult{N: b.N, Percentiles: map[float64]time.Duration{}}
+	if len(durations) == 0 {
+		return result
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	for _, p := range bm.percentiles {
+		idx := int(p / 100 * float64(len(durations)-1))
+		result.Percentiles[p] = durations[idx]
+		b.ReportMetric(float64(durations[idx].Nanoseconds()), fmt.Sprintf("p%g-ns", p))
+	}
+	return result
+}
+
+type replay struct {
+	r      *http.Request
+	body   []byte
+	reader *rewindBody
+}
+
+// reset rewinds the body and drops anything parsed from it last iteration
+func (rp *replay) reset() {
+	rp.reader.Reset(rp.body)
+	rp.r.Body = rp.reader
+	rp.r.Form = nil
+	rp.r.PostForm = nil
+	rp.r.MultipartForm = nil
+}
+
+type rewindBody struct {
+	bytes.Reader
+}
+
+func (*rewindBody) Close() error {
+	return nil
+}
+
+// benchWriter is a ResponseWriter that keeps its buffers between iterations
+type benchWriter struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func (w *benchWriter) reset() {
+	clear(w.header)
+	w.status = 0
+	w.body = w.body[:0]
+}
+
+func (w *benchWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *benchWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *benchWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *benchWriter) Flush() {}
@@ -0,0 +1,112 @@
+package mockhttp_test
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+var pong = []byte(`{"pong":true}`)
+
+func handlePing(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	w.Write(pong)
+}
+
+func handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	user, err := response.DecodeJSON[createUser](w, r, nil)
+	if err != nil {
+		return
+	}
+	response.Created(w, "/users/1", user)
+}
+
+func BenchmarkPing(b *testing.B) {
+	mockhttp.Bench(b, handlePing, mockhttp.NewRequest("POST", "/ping", `{"ping":true}`))
+}
+
+func BenchmarkCreateUser(b *testing.B) {
+	mockhttp.NewBenchmark(handleCreateUser).
+		WithRequests(
+			mockhttp.NewRequest("POST", "/users", `{"name":"wax","email":"wax@example.com"}`),
+			mockhttp.NewRequest("POST", "/users", `{"name":"bee","email":"bee@example.com","age":30}`),
+		).
+		WithPercentiles(50, 90, 99).
+		Run(b)
+}
+
+func TestBench_NoRecorderAllocs(t *testing.T) {
+	result := quickBenchmark(BenchmarkPing)
+
+	assert.Equal(t, int64(0), result.AllocsPerOp())
+}
+
+func TestBench_ReplaysBody(t *testing.T) {
+	var bodies []string
+	quickBenchmark(func(b *testing.B) {
+		bodies = bodies[:0]
+		mockhttp.NewBenchmark(func(w http.ResponseWriter, r *http.Request) {
+			raw, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(raw))
+		}).WithRequests(
+			mockhttp.NewRequest("POST", "/", "a"),
+			mockhttp.NewRequest("POST", "/", "b"),
+		).Run(b)
+	})
+
+	assert.Equal(t, []string{"a", "b", "a", "b"}, bodies[:4])
+}
+
+func TestBench_Percentiles(t *testing.T) {
+	var result *mockhttp.BenchResult
+	bench := quickBenchmark(func(b *testing.B) {
+		result = mockhttp.NewBenchmark(handlePing).
+			WithRequests(mockhttp.NewRequest("POST", "/ping", "")).
+			WithPercentiles(50, 99).
+			Run(b)
+	})
+
+	assert.Equal(t, bench.N, result.N)
+	assert.LessOrEqual(t, result.Percentiles[50], result.Percentiles[99])
+	assert.Contains(t, bench.Extra, "p50-ns")
+	assert.Contains(t, bench.Extra, "p99-ns")
+}
+
+func TestBench_FailsOnServerError(t *testing.T) {
+	result := quickBenchmark(func(b *testing.B) {
+		mockhttp.Bench(b, func(w http.ResponseWriter, r *http.Request) {
+			response.Error(w, 500, "boom", nil)
+		}, mockhttp.NewRequest("GET", "/", ""))
+	})
+
+	assert.Equal(t, 0, result.N)
+}
+
+func TestBench_InvalidPercentile(t *testing.T) {
+	for _, p := range []float64{150, -1} {
+		ran := false
+		result := quickBenchmark(func(b *testing.B) {
+			mockhttp.NewBenchmark(func(w http.ResponseWriter, r *http.Request) {
+				ran = true
+			}).WithRequests(mockhttp.NewRequest("GET", "/", "")).WithPercentiles(50, p).Run(b)
+		})
+
+		assert.Equal(t, 0, result.N)
+		assert.False(t, ran)
+	}
+}
+
+// quickBenchmark runs a benchmark for a fixed number of iterations, so these
+// tests don't take the default benchtime each
+func quickBenchmark(f func(b *testing.B)) testing.BenchmarkResult {
+	benchtime := flag.Lookup("test.benchtime").Value
+	old := benchtime.String()
+	benchtime.Set("200x")
+	defer benchtime.Set(old)
+	return testing.Benchmark(f)
+}
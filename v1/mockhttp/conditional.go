@@ -0,0 +1,75 @@
+package mockhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithIfNoneMatch sets the If-None-Match header to the entity tags
+func (r *Request) WithIfNoneMatch(etags ...string) *Request {
+	return r.SetHeader("If-None-Match", strings.Join(etags, ", "))
+}
+
+// WithIfMatch sets the If-Match header to the entity tags
+func (r *Request) WithIfMatch(etags ...string) *Request {
+	return r.SetHeader("If-Match", strings.Join(etags, ", "))
+}
+
+// WithIfModifiedSince sets the If-Modified-Since header to t as an HTTP date
+func (r *Request) WithIfModifiedSince(t time.Time) *Request {
+	return r.SetHeader("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// WithIfUnmodifiedSince sets the If-Unmodified-Since header to t as an HTTP date
+func (r *Request) WithIfUnmodifiedSince(t time.Time) *Request {
+	return r.SetHeader("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+}
+
+// ValidateConditionalGet fetches a resource, then fetches it again with the
+// ETag and Last-Modified validators from the first response. It returns an
+// error unless the first response is a 200 with at least one validator, and
+// the second is a 304 with no body that repeats the validators.
+// newRequest is called once per fetch and must return a fresh GET or HEAD
+func ValidateConditionalGet(handler http.HandlerFunc, newRequest func() *Request) error {
+	first := newRequest()
+	handler(first.W, first.R)
+	res := first.Result()
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected status %d on the first fetch, but got %d", http.StatusOK, res.StatusCode)
+	}
+	etag := res.Header.Get("ETag")
+	lastModified := res.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return errors.New("expected an ETag or Last-Modified header on the first fetch, but got neither")
+	}
+
+	second := newRequest()
+	if etag != "" {
+		second.SetHeader("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		second.SetHeader("If-Modified-Since", lastModified)
+	}
+	handler(second.W, second.R)
+	cached, err := ToResponse(second.Result())
+	if err != nil {
+		return err
+	}
+	if cached.Status() != http.StatusNotModified {
+		return fmt.Errorf("expected status %d on the conditional fetch, but got %d", http.StatusNotModified, cached.Status())
+	}
+	if cached.Body() != "" {
+		return fmt.Errorf("expected no body on the conditional fetch, but got %q", cached.Body())
+	}
+	if got := second.W.Header().Get("ETag"); got != etag {
+		return fmt.Errorf("expected ETag %s on the conditional fetch, but got %s", etag, got)
+	}
+	if got := second.W.Header().Get("Last-Modified"); got != lastModified {
+		return fmt.Errorf("expected Last-Modified %s on the conditional fetch, but got %s", lastModified, got)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	thingBody     = thing{ID: 1, Name: "wax"}
+	thingETag     = `"v1"`
+	thingModified = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func TestCheckConditional(t *testing.T) {
+	tests := []mockhttp.TestStruct{
+		{
+			Name:     "unconditional",
+			Input:    mockhttp.NewRequest("GET", "/things/1", ""),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name:     "if_none_match_hit",
+			Input:    mockhttp.NewRequest("GET", "/things/1", "").WithIfNoneMatch(`"v0"`, `W/"v1"`),
+			Expected: mockhttp.NewRawResponse().WithStatus(304),
+		},
+		{
+			Name:     "if_none_match_miss",
+			Input:    mockhttp.NewRequest("GET", "/things/1", "").WithIfNoneMatch(`"v0"`),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name:     "if_none_match_unsafe_method",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfNoneMatch("*"),
+			Expected: mockhttp.NewRawResponse().WithStatus(412),
+		},
+		{
+			Name:     "if_modified_since_not_modified",
+			Input:    mockhttp.NewRequest("GET", "/things/1", "").WithIfModifiedSince(thingModified),
+			Expected: mockhttp.NewRawResponse().WithStatus(304),
+		},
+		{
+			Name:     "if_modified_since_modified",
+			Input:    mockhttp.NewRequest("GET", "/things/1", "").WithIfModifiedSince(thingModified.Add(-time.Hour)),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name: "if_none_match_wins_over_if_modified_since",
+			Input: mockhttp.NewRequest("GET", "/things/1", "").
+				WithIfNoneMatch(`"v0"`).
+				WithIfModifiedSince(thingModified),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name:     "if_match_hit",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfMatch(thingETag),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name:     "if_match_weak_never_matches",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfMatch(`W/"v1"`),
+			Expected: mockhttp.NewRawResponse().WithStatus(412),
+		},
+		{
+			Name:     "if_unmodified_since_modified",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfUnmodifiedSince(thingModified.Add(-time.Hour)),
+			Expected: mockhttp.NewRawResponse().WithStatus(412),
+		},
+		{
+			Name:     "if_unmodified_since_unmodified",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfUnmodifiedSince(thingModified),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			conditionalHandler(tt.Input.W, tt.Input.R)
+
+			res, err := mockhttp.ToResponse(tt.Input.Result())
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expected.Status(), res.Status())
+			assert.Equal(t, thingETag, tt.Input.W.Header().Get("ETag"))
+			assert.Equal(t, "Wed, 01 May 2024 12:00:00 GMT", tt.Input.W.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestCheckConditional_WildcardWithoutETag(t *testing.T) {
+	tests := []mockhttp.TestStruct{
+		{
+			Name:     "if_match_any",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfMatch("*"),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name:     "if_none_match_any_unsafe_method",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfNoneMatch("*"),
+			Expected: mockhttp.NewRawResponse().WithStatus(412),
+		},
+		{
+			Name:     "if_none_match_any",
+			Input:    mockhttp.NewRequest("GET", "/things/1", "").WithIfNoneMatch("*"),
+			Expected: mockhttp.NewRawResponse().WithStatus(304),
+		},
+		{
+			Name:     "if_match_etag",
+			Input:    mockhttp.NewRequest("PUT", "/things/1", "").WithIfMatch(thingETag),
+			Expected: mockhttp.NewRawResponse().WithStatus(412),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			lastModifiedHandler(tt.Input.W, tt.Input.R)
+
+			res, err := mockhttp.ToResponse(tt.Input.Result())
+			assert.Nil(t, err)
+			assert.Equal(t, tt.Expected.Status(), res.Status())
+			assert.Empty(t, tt.Input.W.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	strong := response.ETag([]byte("body"), false)
+	weak := response.ETag([]byte("body"), true)
+
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, strong)
+	assert.Equal(t, "W/"+strong, weak)
+	assert.NotEqual(t, strong, response.ETag([]byte("other"), false))
+}
+
+func TestValidateConditionalGet(t *testing.T) {
+	newRequest := func() *mockhttp.Request {
+		return mockhttp.NewRequest("GET", "/things/1", "")
+	}
+
+	assert.Nil(t, mockhttp.ValidateConditionalGet(conditionalHandler, newRequest))
+
+	err := mockhttp.ValidateConditionalGet(successHandler, newRequest)
+	assert.Equal(t, "expected an ETag or Last-Modified header on the first fetch, but got neither", err.Error())
+
+	ignoresConditions := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", thingETag)
+		response.SuccessWithBody(w, thingBody)
+	}
+	err = mockhttp.ValidateConditionalGet(ignoresConditions, newRequest)
+	assert.Equal(t, "expected status 304 on the conditional fetch, but got 200", err.Error())
+}
+
+func conditionalHandler(w http.ResponseWriter, r *http.Request) {
+	if response.CheckConditional(w, r, response.Validators{ETag: thingETag, LastModified: thingModified}) {
+		return
+	}
+	response.SuccessWithBody(w, thingBody)
+}
+
+func lastModifiedHandler(w http.ResponseWriter, r *http.Request) {
+	if response.CheckConditional(w, r, response.Validators{LastModified: thingModified}) {
+		return
+	}
+	response.SuccessWithBody(w, thingBody)
+}
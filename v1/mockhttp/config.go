@@ -0,0 +1,69 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StubConfig is the file format read by LoadStubs
+type StubConfig struct {
+	Stubs []*Stub `json:"stubs" yaml:"stubs"`
+}
+
+// LoadStubs reads stub definitions from a YAML or JSON file, chosen by extension.
+// Relative body files are resolved against the directory of the config file
+func LoadStubs(path string) ([]*Stub, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg StubConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		return nil, fmt.Errorf("unsupported stub config extension: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i, stub := range cfg.Stubs {
+		if stub.Request == nil {
+			stub.Request = &RequestPattern{}
+		}
+		if stub.Response == nil {
+			return nil, fmt.Errorf("stub %d (%s) has no response", i, stub.Request)
+		}
+		if stub.Response.BodyFile != "" && !filepath.IsAbs(stub.Response.BodyFile) {
+			stub.Response.BodyFile = filepath.Join(dir, stub.Response.BodyFile)
+		}
+	}
+	return cfg.Stubs, nil
+}
+
+// Duration is a time.Duration written as a string like "250ms" in config files
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	val, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(val)
+	return nil
+}
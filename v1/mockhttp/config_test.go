@@ -0,0 +1,103 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlConfig = `
+stubs:
+  - request:
+      method: GET
+      path: /users/{id}
+      headers:
+        Accept: application/json
+    response:
+      status: 200
+      headers:
+        Content-Type: application/json
+      bodyFile: user.json
+      delay: 10ms
+  - request:
+      method: POST
+      path: /users
+      jsonBody:
+        name: wax
+    response:
+      status: 201
+      jsonBody:
+        id: 1
+`
+
+const jsonConfig = `{
+  "stubs": [
+    {
+      "request": {"method": "DELETE", "path": "/users/{id}"},
+      "response": {"status": 204}
+    }
+  ]
+}`
+
+func TestLoadStubs_YAML(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "user.json"), `{"id":1,"name":"wax"}`)
+	writeFile(t, filepath.Join(dir, "stubs.yaml"), yamlConfig)
+
+	stubs, err := mockhttp.LoadStubs(filepath.Join(dir, "stubs.yaml"))
+
+	assert.Nil(t, err)
+	assert.Len(t, stubs, 2)
+	assert.Equal(t, "GET /users/{id}", stubs[0].Request.String())
+	assert.Equal(t, filepath.Join(dir, "user.json"), stubs[0].Response.BodyFile)
+	assert.Equal(t, mockhttp.Duration(10*time.Millisecond), stubs[0].Response.Delay)
+
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.SetStubs(stubs...)
+
+	req, _ := http.NewRequest("GET", server.URL()+"/users/1", nil)
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	result, err := mockhttp.ToJSONResponse[user](res)
+	assert.Nil(t, err)
+	assert.Equal(t, "wax", result.Val.Name)
+
+	post(t, server.URL()+"/users", `{"name": "wax"}`)
+	assert.True(t, server.Requests()[1].Matched)
+}
+
+func TestLoadStubs_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stubs.json")
+	writeFile(t, path, jsonConfig)
+
+	stubs, err := mockhttp.LoadStubs(path)
+
+	assert.Nil(t, err)
+	assert.Len(t, stubs, 1)
+	assert.Equal(t, 204, stubs[0].Response.Status)
+}
+
+func TestLoadStubs_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "stubs.txt"), jsonConfig)
+	writeFile(t, filepath.Join(dir, "missing.yaml"), "stubs:\n  - request:\n      path: /\n")
+
+	_, err := mockhttp.LoadStubs(filepath.Join(dir, "stubs.txt"))
+	assert.Equal(t, "unsupported stub config extension: .txt", err.Error())
+
+	_, err = mockhttp.LoadStubs(filepath.Join(dir, "missing.yaml"))
+	assert.Equal(t, "stub 0 (ANY /) has no response", err.Error())
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package mockhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithOrigin sets the Origin header, as a browser does on cross-origin requests
+func (r *Request) WithOrigin(origin string) *Request {
+	return r.SetHeader("Origin", origin)
+}
+
+// Preflight returns the OPTIONS request a browser would send from origin
+// before sending r. Access-Control-Request-Headers lists the headers of r
+// that aren't CORS-safelisted, lowercased and sorted
+func (r *Request) Preflight(origin string) *Request {
+	var headers []string
+	for key, vals := range r.R.Header {
+		if !isSafelistedHeader(key, strings.Join(vals, ", ")) {
+			headers = append(headers, strings.ToLower(key))
+		}
+	}
+	sort.Strings(headers)
+
+	preflight := &Request{
+		W: httptest.NewRecorder(),
+		R: httptest.NewRequest(http.MethodOptions, r.R.URL.String(), nil),
+	}
+	preflight.R.Host = r.R.Host
+	preflight.R = preflight.R.WithContext(r.R.Context())
+	preflight.SetHeader("Origin", origin)
+	preflight.SetHeader("Access-Control-Request-Method", r.R.Method)
+	if len(headers) > 0 {
+		preflight.SetHeader("Access-Control-Request-Headers", strings.Join(headers, ","))
+	}
+	return preflight
+}
+
+func isSafelistedHeader(key, val string) bool {
+	switch http.CanonicalHeaderKey(key) {
+	case "Accept", "Accept-Language", "Content-Language", "Origin":
+		return true
+	case "Content-Type":
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(val, ";")[0]))
+		return mediaType == "application/x-www-form-urlencoded" ||
+			mediaType == "multipart/form-data" ||
+			mediaType == "text/plain"
+	}
+	return false
+}
+
+// CORS is the expected CORS policy for an origin
+type CORS struct {
+	Origin           string
+	Methods          []string
+	Headers          []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge in seconds. Zero means it isn't checked
+	MaxAge int
+}
+
+// NewCORS expects origin to be allowed
+func NewCORS(origin string) *CORS {
+	return &CORS{Origin: origin}
+}
+
+// WithMethods expects each method to be allowed
+func (c *CORS) WithMethods(methods ...string) *CORS {
+	c.Methods = append(c.Methods, methods...)
+	return c
+}
+
+// WithHeaders expects each request header to be allowed
+func (c *CORS) WithHeaders(headers ...string) *CORS {
+	c.Headers = append(c.Headers, headers...)
+	return c
+}
+
+// WithExposedHeaders expects each response header to be exposed to scripts
+func (c *CORS) WithExposedHeaders(headers ...string) *CORS {
+	c.ExposedHeaders = append(c.ExposedHeaders, headers...)
+	return c
+}
+
+// WithCredentials expects cookies and auth headers to be allowed
+func (c *CORS) WithCredentials() *CORS {
+	c.AllowCredentials = true
+	return c
+}
+
+// WithMaxAge expects the preflight to be cacheable for seconds
+func (c *CORS) WithMaxAge(seconds int) *CORS {
+	c.MaxAge = seconds
+	return c
+}
+
+// ValidatePreflight checks a preflight response against the policy
+func (c *CORS) ValidatePreflight(res *http.Response) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("expected a 2xx status for the preflight, but got %d", res.StatusCode)
+	}
+	if err := c.validateOrigin(res); err != nil {
+		return err
+	}
+	allowed := res.Header.Get("Access-Control-Allow-Methods")
+	for _, method := range c.Methods {
+		if !c.listAllows(allowed, method, true) {
+			return fmt.Errorf("expected method %s to be allowed, but Access-Control-Allow-Methods is %q", method, allowed)
+		}
+	}
+	allowed = res.Header.Get("Access-Control-Allow-Headers")
+	for _, header := range c.Headers {
+		if !c.listAllows(allowed, header, false) {
+			return fmt.Errorf("expected header %s to be allowed, but Access-Control-Allow-Headers is %q", header, allowed)
+		}
+	}
+	if c.MaxAge != 0 {
+		maxAge, _ := strconv.Atoi(res.Header.Get("Access-Control-Max-Age"))
+		if maxAge != c.MaxAge {
+			return fmt.Errorf("expected Access-Control-Max-Age %d, but got %q", c.MaxAge, res.Header.Get("Access-Control-Max-Age"))
+		}
+	}
+	return nil
+}
+
+// Validate checks the CORS headers on the response to an actual request
+func (c *CORS) Validate(res *http.Response) error {
+	if err := c.validateOrigin(res); err != nil {
+		return err
+	}
+	exposed := res.Header.Get("Access-Control-Expose-Headers")
+	for _, header := range c.ExposedHeaders {
+		if !c.listAllows(exposed, header, false) {
+			return fmt.Errorf("expected header %s to be exposed, but Access-Control-Expose-Headers is %q", header, exposed)
+		}
+	}
+	return nil
+}
+
+// ValidateDenied checks that the response doesn't allow the origin
+func (c *CORS) ValidateDenied(res *http.Response) error {
+	allowed := res.Header.Get("Access-Control-Allow-Origin")
+	if allowed == "*" || allowed == c.Origin {
+		return fmt.Errorf("expected origin %s to be denied, but Access-Control-Allow-Origin is %q", c.Origin, allowed)
+	}
+	return nil
+}
+
+func (c *CORS) validateOrigin(res *http.Response) error {
+	allowed := res.Header.Get("Access-Control-Allow-Origin")
+	credentials := res.Header.Get("Access-Control-Allow-Credentials") == "true"
+	switch {
+	case allowed == "*" && credentials:
+		return errors.New("expected a specific Access-Control-Allow-Origin with credentials, but got \"*\", which browsers reject")
+	case allowed != "*" && allowed != c.Origin:
+		return fmt.Errorf("expected Access-Control-Allow-Origin %s, but got %q", c.Origin, allowed)
+	case c.AllowCredentials && !credentials:
+		return errors.New("expected Access-Control-Allow-Credentials to be true, but it was not")
+	case !c.AllowCredentials && credentials:
+		return errors.New("expected credentials to be disallowed, but Access-Control-Allow-Credentials is true")
+	}
+	if allowed != "*" && !c.listAllows(strings.Join(res.Header.Values("Vary"), ","), "Origin", false) {
+		return errors.New("expected Vary to include Origin when Access-Control-Allow-Origin echoes the origin, but it did not")
+	}
+	return nil
+}
+
+// listAllows reports whether a comma separated CORS header value contains
+// val. A "*" wildcard only counts without credentials
+func (c *CORS) listAllows(list, val string, caseSensitive bool) bool {
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "*" && !c.AllowCredentials {
+			return true
+		}
+		if item == val || (!caseSensitive && strings.EqualFold(item, val)) {
+			return true
+		}
+	}
+	return false
+}
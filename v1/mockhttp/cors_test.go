@@ -0,0 +1,131 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+const appOrigin = "https://app.example.com"
+
+// cors allows appOrigin with credentials, like most production configs
+func cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+		if r.Header.Get("Origin") != appOrigin {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", appOrigin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-Id")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRequest_Preflight(t *testing.T) {
+	httpReq := mockhttp.NewRequest("DELETE", "/things/1", "").
+		SetHeader("Authorization", "Bearer abc").
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json")
+
+	preflight := httpReq.Preflight(appOrigin)
+
+	assert.Equal(t, "OPTIONS", preflight.R.Method)
+	assert.Equal(t, "/things/1", preflight.R.URL.Path)
+	assert.Equal(t, appOrigin, preflight.R.Header.Get("Origin"))
+	assert.Equal(t, "DELETE", preflight.R.Header.Get("Access-Control-Request-Method"))
+	assert.Equal(t, "authorization,content-type", preflight.R.Header.Get("Access-Control-Request-Headers"))
+
+	simple := mockhttp.NewRequest("POST", "/things", "").SetHeader("Content-Type", "text/plain").Preflight(appOrigin)
+	assert.Empty(t, simple.R.Header.Get("Access-Control-Request-Headers"))
+}
+
+func TestCORS_ValidatePreflight(t *testing.T) {
+	handler := cors(http.HandlerFunc(successHandler))
+	preflight := mockhttp.NewRequest("DELETE", "/things/1", "").
+		SetHeader("Authorization", "Bearer abc").
+		Preflight(appOrigin)
+	handler.ServeHTTP(preflight.W, preflight.R)
+
+	tests := []struct {
+		Name     string
+		Expected *mockhttp.CORS
+		Err      string
+	}{
+		{
+			Name:     "allowed",
+			Expected: mockhttp.NewCORS(appOrigin).WithMethods("DELETE").WithHeaders("authorization").WithCredentials().WithMaxAge(600),
+		},
+		{
+			Name:     "method",
+			Expected: mockhttp.NewCORS(appOrigin).WithMethods("PUT").WithCredentials(),
+			Err:      `expected method PUT to be allowed, but Access-Control-Allow-Methods is "GET, POST, DELETE"`,
+		},
+		{
+			Name:     "header",
+			Expected: mockhttp.NewCORS(appOrigin).WithHeaders("X-Api-Key").WithCredentials(),
+			Err:      `expected header X-Api-Key to be allowed, but Access-Control-Allow-Headers is "Authorization, Content-Type"`,
+		},
+		{
+			Name:     "credentials",
+			Expected: mockhttp.NewCORS(appOrigin),
+			Err:      "expected credentials to be disallowed, but Access-Control-Allow-Credentials is true",
+		},
+		{
+			Name:     "max_age",
+			Expected: mockhttp.NewCORS(appOrigin).WithCredentials().WithMaxAge(3600),
+			Err:      `expected Access-Control-Max-Age 3600, but got "600"`,
+		},
+		{
+			Name:     "origin",
+			Expected: mockhttp.NewCORS("https://evil.example.com").WithCredentials(),
+			Err:      `expected Access-Control-Allow-Origin https://evil.example.com, but got "https://app.example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.Expected.ValidatePreflight(preflight.Result())
+			if tt.Err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.Err)
+			}
+		})
+	}
+}
+
+func TestCORS_Validate(t *testing.T) {
+	handler := cors(http.HandlerFunc(successHandler))
+
+	httpReq := mockhttp.NewRequest("GET", "/things", "").WithOrigin(appOrigin)
+	handler.ServeHTTP(httpReq.W, httpReq.R)
+	expected := mockhttp.NewCORS(appOrigin).WithCredentials().WithExposedHeaders("X-Request-Id")
+	assert.Nil(t, expected.Validate(httpReq.Result()))
+
+	httpReq = mockhttp.NewRequest("GET", "/things", "").WithOrigin("https://evil.example.com")
+	handler.ServeHTTP(httpReq.W, httpReq.R)
+	assert.Nil(t, mockhttp.NewCORS("https://evil.example.com").ValidateDenied(httpReq.Result()))
+}
+
+func TestCORS_WildcardWithCredentials(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/things", "").WithOrigin(appOrigin)
+	httpReq.W.Header().Set("Access-Control-Allow-Origin", "*")
+	httpReq.W.Header().Set("Access-Control-Allow-Credentials", "true")
+
+	err := mockhttp.NewCORS(appOrigin).WithCredentials().Validate(httpReq.Result())
+
+	assert.EqualError(t, err, `expected a specific Access-Control-Allow-Origin with credentials, but got "*", which browsers reject`)
+	assert.EqualError(t, mockhttp.NewCORS(appOrigin).ValidateDenied(httpReq.Result()),
+		`expected origin https://app.example.com to be denied, but Access-Control-Allow-Origin is "*"`)
+}
@@ -0,0 +1,314 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/go-chi/chi"
+)
+
+// Route is a method and path pattern served by a router. Patterns use chi's
+// syntax: {name} matches one segment and a trailing * matches the rest
+type Route struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Pattern
+}
+
+// ChiRoutes lists every route of a chi router, including mounted subrouters
+func ChiRoutes(router chi.Routes) ([]Route, error) {
+	var routes []Route
+	err := chi.Walk(router, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, Route{Method: method, Pattern: pattern})
+		return nil
+	})
+	return routes, err
+}
+
+// Coverage records which routes were exercised, and with which statuses, by
+// the Requests of a test run. Other routers can be covered by listing their
+// routes by hand
+type Coverage struct {
+	mu        sync.Mutex
+	routes    []Route
+	hits      map[Route]map[int]int
+	expected  map[Route][]int
+	unmatched map[string]map[int]int
+}
+
+var (
+	trackedMu sync.Mutex
+	tracked   []*Coverage
+)
+
+// TrackCoverage starts recording the result of every Request against routes.
+// Call it from TestMain and write the report after m.Run
+func TrackCoverage(routes []Route) *Coverage {
+	c := &Coverage{
+		routes:    routes,
+		hits:      map[Route]map[int]int{},
+		expected:  map[Route][]int{},
+		unmatched: map[string]map[int]int{},
+	}
+	trackedMu.Lock()
+	tracked = append(tracked, c)
+	trackedMu.Unlock()
+	return c
+}
+
+// Stop stops recording Requests
+func (c *Coverage) Stop() {
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	for i, t := range tracked {
+		if t == c {
+			tracked = append(tracked[:i], tracked[i+1:]...)
+			return
+		}
+	}
+}
+
+// Expect lists statuses a route should be tested with. The report shows the
+// ones no test produced, and lists expectations for routes that don't exist,
+// such as a mistyped pattern, under UnknownRoutes
+func (c *Coverage) Expect(method, pattern string, statuses ...int) *Coverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	route := Route{Method: method, Pattern: pattern}
+	c.expected[route] = append(c.expected[route], statuses...)
+	return c
+}
+
+// Record counts a response to method and path against the route it matches
+func (c *Coverage) Record(method, path string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	route, ok := c.match(method, path)
+	if !ok {
+		key := method + " " + path
+		if c.unmatched[key] == nil {
+			c.unmatched[key] = map[int]int{}
+		}
+		c.unmatched[key][status]++
+		return
+	}
+	if c.hits[route] == nil {
+		c.hits[route] = map[int]int{}
+	}
+	c.hits[route][status]++
+}
+
+// match finds the most specific route for the request: the one with the most
+// literal segments, preferring {name} segments over a trailing *
+func (c *Coverage) match(method, path string) (Route, bool) {
+	var best Route
+	bestScore := -1
+	for _, route := range c.routes {
+		if route.Method != method {
+			continue
+		}
+		if _, ok := matchPath(route.Pattern, path); !ok {
+			continue
+		}
+		score := 0
+		for _, part := range strings.Split(strings.Trim(route.Pattern, "/"), "/") {
+			switch {
+			case part == "*":
+			case strings.HasPrefix(part, "{"):
+				score++
+			default:
+				score += 2
+			}
+		}
+		if score > bestScore {
+			best, bestScore = route, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+func recordCoverage(method, path string, status int) {
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	for _, c := range tracked {
+		c.Record(method, path, status)
+	}
+}
+
+// RouteCoverage is how one route was exercised
+type RouteCoverage struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	// Statuses counts the responses by status code
+	Statuses map[int]int `json:"statuses"`
+	// Missing lists expected statuses that no test produced
+	Missing []int `json:"missing,omitempty"`
+}
+
+// Hit reports whether any test exercised the route
+func (r RouteCoverage) Hit() bool {
+	return len(r.Statuses) > 0
+}
+
+// UnmatchedRequest is a request that didn't match any route
+type UnmatchedRequest struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Statuses map[int]int `json:"statuses"`
+}
+
+// CoverageReport summarises a Coverage
+type CoverageReport struct {
+	Covered   int                `json:"covered"`
+	Total     int                `json:"total"`
+	Routes    []RouteCoverage    `json:"routes"`
+	Unmatched []UnmatchedRequest `json:"unmatched"`
+	// UnknownRoutes lists Expect calls for routes that aren't being tracked,
+	// with every expected status as missing
+	UnknownRoutes []RouteCoverage `json:"unknownRoutes,omitempty"`
+}
+
+// Report returns the coverage so far, with routes sorted by pattern and method
+func (c *Coverage) Report() CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := CoverageReport{Routes: []RouteCoverage{}, Unmatched: []UnmatchedRequest{}}
+	for _, route := range c.routes {
+		rc := RouteCoverage{Method: route.Method, Pattern: route.Pattern, Statuses: map[int]int{}}
+		for status, n := range c.hits[route] {
+			rc.Statuses[status] = n
+		}
+		for _, status := range c.expected[route] {
+			if rc.Statuses[status] == 0 {
+				rc.Missing = append(rc.Missing, status)
+			}
+		}
+		if rc.Hit() {
+			report.Covered++
+		}
+		report.Routes = append(report.Routes, rc)
+	}
+	report.Total = len(report.Routes)
+	sortRoutes(report.Routes)
+
+	known := make(map[Route]bool, len(c.routes))
+	for _, route := range c.routes {
+		known[route] = true
+	}
+	for route, statuses := range c.expected {
+		if !known[route] {
+			report.UnknownRoutes = append(report.UnknownRoutes, RouteCoverage{
+				Method:  route.Method,
+				Pattern: route.Pattern,
+				Missing: append([]int(nil), statuses...),
+			})
+		}
+	}
+	sortRoutes(report.UnknownRoutes)
+
+	for key, statuses := range c.unmatched {
+		method, path, _ := strings.Cut(key, " ")
+		report.Unmatched = append(report.Unmatched, UnmatchedRequest{Method: method, Path: path, Statuses: statuses})
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		a, b := report.Unmatched[i], report.Unmatched[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return report
+}
+
+func sortRoutes(routes []RouteCoverage) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Pattern != b.Pattern {
+			return a.Pattern < b.Pattern
+		}
+		return a.Method < b.Method
+	})
+}
+
+// WriteText writes the report as an aligned table
+func (r CoverageReport) WriteText(w io.Writer) error {
+	percent := 0.0
+	if r.Total > 0 {
+		percent = float64(r.Covered) / float64(r.Total) * 100
+	}
+	fmt.Fprintf(w, "route coverage: %d/%d routes (%.1f%%)\n", r.Covered, r.Total, percent)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, route := range r.Routes {
+		result := "never hit"
+		if route.Hit() {
+			result = formatStatuses(route.Statuses)
+		}
+		if len(route.Missing) > 0 {
+			result += " (missing " + joinStatuses(route.Missing) + ")"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", route.Method, route.Pattern, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Unmatched) > 0 {
+		fmt.Fprintln(w, "unmatched requests:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, req := range r.Unmatched {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", req.Method, req.Path, formatStatuses(req.Statuses))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.UnknownRoutes) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "expected statuses for unknown routes:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, route := range r.UnknownRoutes {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", route.Method, route.Pattern, joinStatuses(route.Missing))
+	}
+	return tw.Flush()
+}
+
+func joinStatuses(statuses []int) string {
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprint(status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteJSON writes the report as indented JSON, for CI dashboards
+func (r CoverageReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func formatStatuses(statuses map[int]int) string {
+	codes := make([]int, 0, len(statuses))
+	for status := range statuses {
+		codes = append(codes, status)
+	}
+	sort.Ints(codes)
+	parts := make([]string, len(codes))
+	for i, status := range codes {
+		parts[i] = fmt.Sprintf("%d x%d", status, statuses[status])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,117 @@
+package mockhttp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func thingsRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Route("/things", func(r chi.Router) {
+		r.Get("/", successHandler)
+		r.Get("/new", successHandler)
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if chi.URLParam(r, "id") != "1" {
+				response.Error(w, http.StatusNotFound, "no thing with that id", nil)
+				return
+			}
+			response.Success(w)
+		})
+		r.Delete("/{id}", successHandler)
+	})
+	return r
+}
+
+func TestChiRoutes(t *testing.T) {
+	routes, err := mockhttp.ChiRoutes(thingsRouter())
+
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []mockhttp.Route{
+		{Method: "GET", Pattern: "/things/"},
+		{Method: "GET", Pattern: "/things/new"},
+		{Method: "GET", Pattern: "/things/{id}"},
+		{Method: "DELETE", Pattern: "/things/{id}"},
+	}, routes)
+}
+
+func TestCoverage(t *testing.T) {
+	router := thingsRouter()
+	routes, err := mockhttp.ChiRoutes(router)
+	assert.Nil(t, err)
+	coverage := mockhttp.TrackCoverage(routes).Expect("DELETE", "/things/{id}", 200, 404)
+	defer coverage.Stop()
+
+	for _, path := range []string{"/things", "/things/new", "/things/1", "/things/2", "/nope"} {
+		httpReq := mockhttp.NewRequest("GET", path, "")
+		router.ServeHTTP(httpReq.W, httpReq.R)
+		httpReq.Result()
+		httpReq.Result()
+	}
+	httpReq := mockhttp.NewRequest("DELETE", "/things/1", "")
+	router.ServeHTTP(httpReq.W, httpReq.R)
+	httpReq.Result()
+
+	report := coverage.Report()
+	assert.Equal(t, 4, report.Covered)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, map[int]int{200: 1}, report.Routes[1].Statuses)
+	assert.Equal(t, map[int]int{200: 1, 404: 1}, report.Routes[3].Statuses)
+	assert.Equal(t, []int{404}, report.Routes[2].Missing)
+
+	var text bytes.Buffer
+	assert.Nil(t, report.WriteText(&text))
+	assert.Equal(t, `route coverage: 4/4 routes (100.0%)
+  GET     /things/      200 x1
+  GET     /things/new   200 x1
+  DELETE  /things/{id}  200 x1 (missing 404)
+  GET     /things/{id}  200 x1, 404 x1
+unmatched requests:
+  GET  /nope  404 x1
+`, text.String())
+
+	var raw bytes.Buffer
+	assert.Nil(t, report.WriteJSON(&raw))
+	var decoded mockhttp.CoverageReport
+	assert.Nil(t, json.Unmarshal(raw.Bytes(), &decoded))
+	assert.Equal(t, report, decoded)
+}
+
+func TestCoverage_NeverHit(t *testing.T) {
+	coverage := mockhttp.TrackCoverage([]mockhttp.Route{{Method: "GET", Pattern: "/things"}})
+	coverage.Stop()
+
+	httpReq := mockhttp.NewRequest("GET", "/things", "")
+	successHandler(httpReq.W, httpReq.R)
+	httpReq.Result()
+
+	var text bytes.Buffer
+	assert.Nil(t, coverage.Report().WriteText(&text))
+	assert.Equal(t, "route coverage: 0/1 routes (0.0%)\n  GET  /things  never hit\n", text.String())
+}
+
+func TestCoverage_UnknownRouteExpectation(t *testing.T) {
+	coverage := mockhttp.TrackCoverage([]mockhttp.Route{{Method: "GET", Pattern: "/things"}}).
+		Expect("GET", "/things", 200).
+		Expect("DELETE", "/thing/{id}", 204, 404)
+	coverage.Stop()
+
+	report := coverage.Report()
+	assert.Equal(t, []mockhttp.RouteCoverage{
+		{Method: "DELETE", Pattern: "/thing/{id}", Missing: []int{204, 404}},
+	}, report.UnknownRoutes)
+
+	var text bytes.Buffer
+	assert.Nil(t, report.WriteText(&text))
+	assert.Equal(t, `route coverage: 0/1 routes (0.0%)
+  GET  /things  never hit (missing 200)
+expected statuses for unknown routes:
+  DELETE  /thing/{id}  204, 404
+`, text.String())
+}
@@ -0,0 +1,145 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+type createUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+func (c createUser) Validate() error {
+	var errs response.FieldErrors
+	if c.Name == "" {
+		errs = append(errs, response.FieldError{Field: "name", Message: "is required"})
+	}
+	if !strings.Contains(c.Email, "@") {
+		errs = append(errs, response.FieldError{Field: "email", Message: "must be an email address"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Input    *mockhttp.Request
+		Expected *mockhttp.JSONResponse[mockhttp.ServerError]
+	}{
+		{
+			Name:     "valid",
+			Input:    mockhttp.NewRequest("POST", "/users", `{"name":"wax","email":"wax@example.com","age":3}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().WithStatus(201),
+		},
+		{
+			Name: "wrong_content_type",
+			Input: mockhttp.NewRequest("POST", "/users", `name=wax`).
+				SetHeader("Content-Type", "application/x-www-form-urlencoded"),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(415, &mockhttp.ServerError{
+					Status:       "unsupported media type",
+					DebugMessage: "expected a JSON request body",
+					Error:        `unsupported Content-Type "application/x-www-form-urlencoded"`,
+				}),
+		},
+		{
+			Name:  "too_large",
+			Input: mockhttp.NewRequest("POST", "/users", `{"name":"`+strings.Repeat("a", 100)+`"}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(413, &mockhttp.ServerError{
+					Status:       "request entity too large",
+					DebugMessage: "request body must not be larger than 64 bytes",
+					Error:        "http: request body too large",
+				}),
+		},
+		{
+			Name:  "empty",
+			Input: mockhttp.NewRequest("POST", "/users", ""),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "invalid request body",
+					Error:        "request body is empty",
+				}),
+		},
+		{
+			Name:  "unknown_field",
+			Input: mockhttp.NewRequest("POST", "/users", `{"nmae":"wax"}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "invalid request body",
+					Error:        "nmae: unknown field",
+					Fields:       []mockhttp.FieldError{{Field: "nmae", Message: "unknown field"}},
+				}),
+		},
+		{
+			Name:  "wrong_type",
+			Input: mockhttp.NewRequest("POST", "/users", `{"age":"three"}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "invalid request body",
+					Error:        "age: expected int, but got string",
+					Fields:       []mockhttp.FieldError{{Field: "age", Message: "expected int, but got string"}},
+				}),
+		},
+		{
+			Name:  "trailing_data",
+			Input: mockhttp.NewRequest("POST", "/users", `{"name":"wax"} {}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "invalid request body",
+					Error:        "request body must contain a single JSON value",
+				}),
+		},
+		{
+			Name:  "invalid_fields",
+			Input: mockhttp.NewRequest("POST", "/users", `{"email":"wax"}`),
+			Expected: mockhttp.NewJSONResponse[mockhttp.ServerError]().
+				WithFailure(400, &mockhttp.ServerError{
+					Status:       "bad request",
+					DebugMessage: "invalid request body",
+					Error:        "name: is required; email: must be an email address",
+					Fields: []mockhttp.FieldError{
+						{Field: "name", Message: "is required"},
+						{Field: "email", Message: "must be an email address"},
+					},
+				}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			createUserHandler(tt.Input.W, tt.Input.R)
+
+			res := tt.Input.Result()
+			if tt.Expected.Val == nil {
+				assert.Equal(t, tt.Expected.Status(), res.StatusCode)
+				return
+			}
+			result, err := mockhttp.ToJSONResponse[mockhttp.ServerError](res)
+			assert.Nil(t, err)
+			assert.Nil(t, tt.Expected.WithValidationFunc(mockhttp.ValidateErrors).Validate(result))
+		})
+	}
+}
+
+func createUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := response.DecodeJSON[createUser](w, r, &response.DecodeOptions{MaxBytes: 64})
+	if err != nil {
+		return
+	}
+	response.Created(w, "/users/1", user)
+}
@@ -3,9 +3,17 @@ package mockhttp
 import "fmt"
 
 type ServerError struct {
-	Status       string `json:"status"`
-	DebugMessage string `json:"message"`
-	Error        string `json:"error"`
+	Status       string       `json:"status"`
+	DebugMessage string       `json:"message"`
+	Error        string       `json:"error"`
+	Code         string       `json:"code,omitempty"`
+	Fields       []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError is a problem with one field of a request body, as listed in ServerError.Fields
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func ValidateErrors(expected, result ServerError) error {
@@ -18,5 +26,17 @@ func ValidateErrors(expected, result ServerError) error {
 	if expected.Error != result.Error {
 		return fmt.Errorf("expected error: %s, but got %s", expected.Error, result.Error)
 	}
+	if expected.Code != result.Code {
+		return fmt.Errorf("expected code: %s, but got %s", expected.Code, result.Code)
+	}
+	if len(expected.Fields) != len(result.Fields) {
+		return fmt.Errorf("expected %d field error(s), but got %d: %v", len(expected.Fields), len(result.Fields), result.Fields)
+	}
+	for i := range expected.Fields {
+		if expected.Fields[i] != result.Fields[i] {
+			return fmt.Errorf("expected field error: %s: %s, but got %s: %s",
+				expected.Fields[i].Field, expected.Fields[i].Message, result.Fields[i].Field, result.Fields[i].Message)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,95 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_StatusText(t *testing.T) {
+	tests := []struct {
+		status int
+		text   string
+	}{
+		{400, "bad request"},
+		{404, "not found"},
+		{409, "conflict"},
+		{422, "unprocessable entity"},
+		{429, "too many requests"},
+		{500, "internal error"},
+		{503, "service unavailable"},
+		{599, "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			httpReq := mockhttp.NewRequest("GET", "/", "")
+			response.Error(httpReq.W, tt.status, "", nil)
+
+			res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+			assert.Nil(t, err)
+			assert.Equal(t, tt.status, res.Status())
+			assert.Equal(t, tt.text, res.Val.Status)
+			assert.Equal(t, "", res.Val.Code)
+		})
+	}
+}
+
+func TestError_RegisteredStatus(t *testing.T) {
+	response.RegisterStatus(598, "quota exceeded", "QUOTA_EXCEEDED")
+	t.Cleanup(func() { response.UnregisterStatus(598) })
+
+	expected := mockhttp.NewJSONResponse[mockhttp.ServerError]().
+		WithFailure(598, &mockhttp.ServerError{
+			Status:       "quota exceeded",
+			DebugMessage: "slow down",
+			Code:         "QUOTA_EXCEEDED",
+		}).
+		WithValidationFunc(mockhttp.ValidateErrors)
+
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	response.Error(httpReq.W, 598, "slow down", nil)
+	result, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Nil(t, expected.Validate(result))
+}
+
+func TestError_UnregisterStatus(t *testing.T) {
+	response.RegisterStatus(404, "no such thing", "NOT_FOUND")
+	response.RegisterStatus(598, "quota exceeded", "QUOTA_EXCEEDED")
+	response.UnregisterStatus(404)
+	response.UnregisterStatus(598)
+
+	for status, text := range map[int]string{404: "not found", 598: "internal error"} {
+		httpReq := mockhttp.NewRequest("GET", "/", "")
+		response.Error(httpReq.W, status, "", nil)
+		res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+
+		assert.Nil(t, err)
+		assert.Equal(t, text, res.Val.Status)
+		assert.Equal(t, "", res.Val.Code)
+	}
+}
+
+func TestErrorWithCode(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	response.ErrorWithCode(httpReq.W, 409, "USER_EXISTS", "user already exists", nil)
+
+	res, err := mockhttp.ToResponse(httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"code":"USER_EXISTS","message":"user already exists","status":"conflict"}`, res.Body())
+}
+
+func TestValidateErrors_Code(t *testing.T) {
+	err := mockhttp.ValidateErrors(
+		mockhttp.ServerError{Status: "conflict", Code: "USER_EXISTS"},
+		mockhttp.ServerError{Status: "conflict", Code: "EMAIL_EXISTS"},
+	)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "expected code: USER_EXISTS, but got EMAIL_EXISTS", err.Error())
+}
@@ -0,0 +1,388 @@
+package mockhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+)
+
+type fuzzSlotKind int
+
+const (
+	pathSlot fuzzSlotKind = iota
+	querySlot
+	headerSlot
+	jsonSlot
+)
+
+type fuzzSlot struct {
+	kind fuzzSlotKind
+	name string
+	seed string
+}
+
+// FuzzProperty is checked against every response while fuzzing. body is the
+// full response body
+type FuzzProperty func(res *http.Response, body []byte) error
+
+// FuzzTemplate describes a request with slots that Fuzz fills with generated
+// strings. An empty value leaves a query, header or JSON field slot out of the
+// request. JSON field slots that hold valid JSON are sent as that JSON value,
+// and as a string otherwise, so the fuzzer can find numbers, nulls and objects
+type FuzzTemplate struct {
+	Method string
+	Path   string
+
+	body       map[string]interface{}
+	slots      []fuzzSlot
+	seeds      [][]string
+	properties []FuzzProperty
+}
+
+// NewFuzzTemplate returns a template for method and path, which checks that
+// no response is a 5xx and that JSON responses are valid JSON
+func NewFuzzTemplate(method, path string) *FuzzTemplate {
+	return &FuzzTemplate{
+		Method:     method,
+		Path:       path,
+		properties: []FuzzProperty{No5xx(), ValidJSON()},
+	}
+}
+
+// WithPathParam fuzzes the {name} segment of the path
+func (t *FuzzTemplate) WithPathParam(name, seed string) *FuzzTemplate {
+	t.slots = append(t.slots, fuzzSlot{kind: pathSlot, name: name, seed: seed})
+	return t
+}
+
+// WithQuery fuzzes the query value for key
+func (t *FuzzTemplate) WithQuery(key, seed string) *FuzzTemplate {
+	t.slots = append(t.slots, fuzzSlot{kind: querySlot, name: key, seed: seed})
+	return t
+}
+
+// WithHeader fuzzes the value of a header
+func (t *FuzzTemplate) WithHeader(key, seed string) *FuzzTemplate {
+	t.slots = append(t.slots, fuzzSlot{kind: headerSlot, name: http.CanonicalHeaderKey(key), seed: seed})
+	return t
+}
+
+// WithJSONBody sets the fixed fields of the JSON body. It must marshal to an object
+func (t *FuzzTemplate) WithJSONBody(val interface{}) *FuzzTemplate {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		panic(fmt.Sprintf("unable to marshal fuzz template body: %v", err))
+	}
+	t.body = map[string]interface{}{}
+	if err := json.Unmarshal(raw, &t.body); err != nil {
+		panic(fmt.Sprintf("expected fuzz template body to be a JSON object, but got %s", raw))
+	}
+	return t
+}
+
+// WithJSONField fuzzes a top level field of the JSON body
+func (t *FuzzTemplate) WithJSONField(name, seed string) *FuzzTemplate {
+	t.slots = append(t.slots, fuzzSlot{kind: jsonSlot, name: name, seed: seed})
+	return t
+}
+
+// WithProperties adds properties to check on every response
+func (t *FuzzTemplate) WithProperties(props ...FuzzProperty) *FuzzTemplate {
+	t.properties = append(t.properties, props...)
+	return t
+}
+
+// WithSeedRequests adds the slot values of existing requests to the seed
+// corpus. Path params are read from faked chi params first, then the path
+func (t *FuzzTemplate) WithSeedRequests(reqs ...*Request) *FuzzTemplate {
+	for _, req := range reqs {
+		t.seeds = append(t.seeds, t.seedFrom(req))
+	}
+	return t
+}
+
+// WithSeedTests adds the inputs of table test cases to the seed corpus
+func (t *FuzzTemplate) WithSeedTests(tests ...TestStruct) *FuzzTemplate {
+	for _, tt := range tests {
+		t.WithSeedRequests(tt.Input)
+	}
+	return t
+}
+
+func (t *FuzzTemplate) seedFrom(req *Request) []string {
+	pathParams, _ := matchPath(t.Path, req.R.URL.Path)
+	rctx := chi.RouteContext(req.R.Context())
+
+	var body map[string]json.RawMessage
+	if req.R.Body != nil {
+		raw, _ := io.ReadAll(req.R.Body)
+		req.R.Body = io.NopCloser(bytes.NewReader(raw))
+		json.Unmarshal(raw, &body)
+	}
+
+	vals := make([]string, len(t.slots))
+	for i, slot := range t.slots {
+		switch slot.kind {
+		case pathSlot:
+			vals[i] = pathParams[slot.name]
+			if rctx != nil && rctx.URLParam(slot.name) != "" {
+				vals[i] = rctx.URLParam(slot.name)
+			}
+		case querySlot:
+			vals[i] = req.R.URL.Query().Get(slot.name)
+		case headerSlot:
+			vals[i] = req.R.Header.Get(slot.name)
+		case jsonSlot:
+			var s string
+			if err := json.Unmarshal(body[slot.name], &s); err == nil {
+				vals[i] = s
+			} else {
+				vals[i] = string(body[slot.name])
+			}
+		}
+	}
+	return vals
+}
+
+// Request builds the request for one set of slot values, in the order the
+// slots were added. Use it to turn a failing fuzz input into a test case
+func (t *FuzzTemplate) Request(vals ...string) *Request {
+	path := t.Path
+	params := map[string]string{}
+	query := url.Values{}
+	header := map[string]string{}
+	var body map[string]interface{}
+	if t.body != nil {
+		body = map[string]interface{}{}
+		for key, val := range t.body {
+			body[key] = val
+		}
+	}
+
+	for i, slot := range t.slots {
+		val := ""
+		if i < len(vals) {
+			val = vals[i]
+		}
+		switch slot.kind {
+		case pathSlot:
+			params[slot.name] = val
+			path = strings.ReplaceAll(path, "{"+slot.name+"}", url.PathEscape(val))
+		case querySlot:
+			if val != "" {
+				query.Set(slot.name, val)
+			}
+		case headerSlot:
+			if val != "" {
+				header[slot.name] = val
+			}
+		case jsonSlot:
+			if val == "" {
+				continue
+			}
+			if body == nil {
+				body = map[string]interface{}{}
+			}
+			if json.Valid([]byte(val)) {
+				body[slot.name] = json.RawMessage(val)
+			} else {
+				body[slot.name] = val
+			}
+		}
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var raw []byte
+	if body != nil {
+		raw, _ = json.Marshal(body)
+	}
+	req := NewRequest(t.Method, path, string(raw)).WithHeaders(header)
+	if body != nil {
+		req.SetHeader("Content-Type", "application/json")
+	}
+	if len(params) > 0 {
+		req.WithPathParams(Chi, params)
+	}
+	return req
+}
+
+// Check sends one request built from vals to handler and returns an error if
+// the handler panics or a property fails
+func (t *FuzzTemplate) Check(handler http.HandlerFunc, vals ...string) error {
+	req := t.Request(vals...)
+	desc := describeRequest(req)
+	if err := checkProperties(handler, req, t.properties); err != nil {
+		return fmt.Errorf("%s: %w", desc, err)
+	}
+	return nil
+}
+
+// checkProperties serves req with handler and checks every property against
+// the response. A panic in the handler is returned as an error
+func checkProperties(handler http.HandlerFunc, req *Request, props []FuzzProperty) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("handler panicked: %v", v)
+		}
+	}()
+	handler(req.W, req.R)
+
+	res := req.Result()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	for _, prop := range props {
+		if err := prop(res, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeRequest(req *Request) string {
+	desc := req.R.Method + " " + req.R.URL.RequestURI()
+	for _, key := range sortedKeys(flattenHeader(req.R.Header)) {
+		desc += fmt.Sprintf(" %s=%q", key, req.R.Header.Get(key))
+	}
+	if req.R.Body != nil {
+		raw, _ := io.ReadAll(req.R.Body)
+		req.R.Body = io.NopCloser(bytes.NewReader(raw))
+		if len(raw) > 0 {
+			desc += " " + string(raw)
+		}
+	}
+	return desc
+}
+
+func flattenHeader(h http.Header) map[string]string {
+	flat := map[string]string{}
+	for key := range h {
+		flat[key] = h.Get(key)
+	}
+	return flat
+}
+
+// Fuzz fuzzes every slot of the template with go test -fuzz, checking that
+// handler never panics and every property holds. The slot seeds and any seed
+// requests form the seed corpus, which plain go test runs as regular tests
+func Fuzz(f *testing.F, handler http.HandlerFunc, template *FuzzTemplate) {
+	if len(template.slots) == 0 {
+		f.Fatal("expected fuzz template to have at least one slot, but it has none")
+	}
+
+	seed := make([]interface{}, len(template.slots))
+	for i, slot := range template.slots {
+		seed[i] = slot.seed
+	}
+	f.Add(seed...)
+	for _, vals := range template.seeds {
+		args := make([]interface{}, len(vals))
+		for i, val := range vals {
+			args[i] = val
+		}
+		f.Add(args...)
+	}
+
+	// testing.F needs a func with one string argument per slot, which is only
+	// known at run time
+	in := []reflect.Type{reflect.TypeOf((*testing.T)(nil))}
+	for range template.slots {
+		in = append(in, reflect.TypeOf(""))
+	}
+	fn := reflect.MakeFunc(reflect.FuncOf(in, nil, false), func(args []reflect.Value) []reflect.Value {
+		t := args[0].Interface().(*testing.T)
+		vals := make([]string, len(args)-1)
+		for i, arg := range args[1:] {
+			vals[i] = arg.String()
+		}
+		if !validHeaderValues(template, vals) {
+			t.Skip("header values can't contain control characters")
+		}
+		if err := template.Check(handler, vals...); err != nil {
+			t.Fatal(err)
+		}
+		return nil
+	})
+	f.Fuzz(fn.Interface())
+}
+
+// validHeaderValues reports whether a real server would accept the header
+// values, since net/http rejects control characters before any handler runs
+func validHeaderValues(t *FuzzTemplate, vals []string) bool {
+	for i, slot := range t.slots {
+		if slot.kind != headerSlot {
+			continue
+		}
+		for _, c := range vals[i] {
+			if (c < ' ' && c != '\t') || c == 0x7f {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// No5xx fails on any server error
+func No5xx() FuzzProperty {
+	return func(res *http.Response, body []byte) error {
+		if res.StatusCode >= 500 {
+			return fmt.Errorf("expected a status below 500, but got %d: %s", res.StatusCode, body)
+		}
+		return nil
+	}
+}
+
+// ValidJSON fails if a response with a JSON Content-Type isn't valid JSON
+func ValidJSON() FuzzProperty {
+	return func(res *http.Response, body []byte) error {
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return nil
+		}
+		if !json.Valid(body) {
+			return fmt.Errorf("expected a valid JSON body, but got %q", body)
+		}
+		return nil
+	}
+}
+
+// ConformsTo fails if the body of a response with one of the statuses doesn't
+// decode into T without unknown fields. With no statuses, every 2xx is checked
+func ConformsTo[T any](statuses ...int) FuzzProperty {
+	return func(res *http.Response, body []byte) error {
+		if !statusIn(res.StatusCode, statuses) {
+			return nil
+		}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		var val T
+		if err := dec.Decode(&val); err != nil {
+			return fmt.Errorf("expected a %d body to decode into %T, but got %w", res.StatusCode, val, err)
+		}
+		return nil
+	}
+}
+
+func statusIn(status int, statuses []int) bool {
+	if len(statuses) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleRenameThing(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "id must be a number", err)
+		return
+	}
+	body, err := response.DecodeJSON[thing](w, r, nil)
+	if err != nil {
+		return
+	}
+	body.ID = id
+	response.SuccessWithBody(w, body)
+}
+
+func renameTemplate() *mockhttp.FuzzTemplate {
+	return mockhttp.NewFuzzTemplate("PUT", "/things/{id}").
+		WithPathParam("id", "1").
+		WithQuery("dryRun", "true").
+		WithHeader("X-Tenant", "acme").
+		WithJSONField("name", "wax").
+		WithProperties(mockhttp.ConformsTo[thing](), mockhttp.ConformsTo[mockhttp.ServerError](400, 413, 415))
+}
+
+func FuzzRenameThing(f *testing.F) {
+	mockhttp.Fuzz(f, handleRenameThing, renameTemplate().WithSeedTests(
+		mockhttp.TestStruct{
+			Name: "rename",
+			Input: mockhttp.NewRequest("PUT", "/things/:id", `{"name":"bee"}`).
+				WithPathParams(mockhttp.Chi, map[string]string{"id": "2"}),
+		},
+		mockhttp.TestStruct{
+			Name:  "bad_name",
+			Input: mockhttp.NewRequest("PUT", "/things/3?dryRun=false", `{"name":7}`),
+		},
+	))
+}
+
+func TestFuzzTemplate_Request(t *testing.T) {
+	httpReq := renameTemplate().Request("a/b", "", "globex", `{"first":"wax"}`)
+
+	assert.Equal(t, "/things/a%2Fb", httpReq.R.URL.EscapedPath())
+	assert.Equal(t, "", httpReq.R.URL.RawQuery)
+	assert.Equal(t, "a/b", chi.URLParam(httpReq.R, "id"))
+	assert.Equal(t, "globex", httpReq.R.Header.Get("X-Tenant"))
+	assert.Equal(t, "application/json", httpReq.R.Header.Get("Content-Type"))
+
+	res, err := mockhttp.ToResponse(httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.Status())
+}
+
+func TestFuzzTemplate_Check(t *testing.T) {
+	template := renameTemplate()
+
+	assert.Nil(t, template.Check(handleRenameThing, "1", "true", "acme", "wax"))
+	assert.Nil(t, template.Check(handleRenameThing, "x", "", "", "null"))
+
+	err := template.Check(func(w http.ResponseWriter, r *http.Request) {
+		var names []string
+		_ = names[len(r.URL.Query().Get("dryRun"))]
+	}, "1", "true", "", "")
+	assert.EqualError(t, err, `PUT /things/1?dryRun=true: handler panicked: runtime error: index out of range [4] with length 0`)
+
+	err = template.Check(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, 500, "boom", nil)
+	}, "1", "", "acme", "")
+	assert.EqualError(t, err, `PUT /things/1 X-Tenant="acme": expected a status below 500, but got 500: {"message":"boom","status":"internal error"}`)
+
+	err = template.Check(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":`))
+	}, "1", "", "", "")
+	assert.EqualError(t, err, `PUT /things/1: expected a valid JSON body, but got "{\"id\":"`)
+
+	err = template.Check(func(w http.ResponseWriter, r *http.Request) {
+		response.SuccessWithBody(w, map[string]interface{}{"id": 1, "colour": "red"})
+	}, "1", "", "", "wax")
+	assert.EqualError(t, err, `PUT /things/1 Content-Type="application/json" {"name":"wax"}: expected a 200 body to decode into mockhttp_test.thing, but got json: unknown field "colour"`)
+}
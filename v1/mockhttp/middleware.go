@@ -0,0 +1,71 @@
+package mockhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Middleware is the func(http.Handler) http.Handler shape used by chi and net/http
+type Middleware func(http.Handler) http.Handler
+
+// MiddlewareResult records what a middleware chain did with a request
+type MiddlewareResult struct {
+	// NextCalled reports whether the request made it through every middleware
+	NextCalled bool
+	// NextRequest is the request the next handler received, including any
+	// headers and context values the middleware added. It is nil if
+	// NextCalled is false
+	NextRequest *http.Request
+	// Response is what the client received
+	Response *http.Response
+}
+
+// ServeMiddleware runs the request through the middlewares in front of a
+// recording next handler. The first middleware is the outermost, matching the
+// order of chi's Use. If next is nil, the next handler writes an empty 200
+func (r *Request) ServeMiddleware(next http.HandlerFunc, middlewares ...Middleware) *MiddlewareResult {
+	result := &MiddlewareResult{}
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result.NextCalled = true
+		result.NextRequest = req
+		if next != nil {
+			next(w, req)
+		}
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	h.ServeHTTP(r.W, r.R)
+	result.Response = r.Result()
+	return result
+}
+
+// Value returns the context value the next handler saw for key, or nil if
+// next was never called
+func (m *MiddlewareResult) Value(key interface{}) interface{} {
+	if m.NextRequest == nil {
+		return nil
+	}
+	return m.NextRequest.Context().Value(key)
+}
+
+// ExpectNext returns an error if the middleware did not call the next handler
+func (m *MiddlewareResult) ExpectNext() error {
+	if !m.NextCalled {
+		return fmt.Errorf("expected next handler to be called, but the middleware responded with status %d", m.Response.StatusCode)
+	}
+	return nil
+}
+
+// ExpectShortCircuit returns an error if the middleware called the next
+// handler, or if it responded with a status other than status
+func (m *MiddlewareResult) ExpectShortCircuit(status int) error {
+	if m.NextCalled {
+		return errors.New("expected middleware to respond without calling next handler, but next handler was called")
+	}
+	if m.Response.StatusCode != status {
+		return fmt.Errorf("expected status %d, but got %d", status, m.Response.StatusCode)
+	}
+	return nil
+}
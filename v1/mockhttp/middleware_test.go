@@ -0,0 +1,97 @@
+package mockhttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIDKey struct{}
+
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if id == "" {
+			id = "generated"
+			r.Header.Set("X-Request-Id", id)
+		}
+		w.Header().Set("X-Request-Id", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			response.Error(w, http.StatusUnauthorized, "missing credentials", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				response.Error(w, http.StatusInternalServerError, "recovered", fmt.Errorf("%v", v))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestServeMiddleware_CallsNext(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/users", "")
+
+	result := httpReq.ServeMiddleware(nil, requestID)
+
+	assert.Nil(t, result.ExpectNext())
+	assert.Equal(t, "generated", result.Value(requestIDKey{}))
+	assert.Equal(t, "generated", result.NextRequest.Header.Get("X-Request-Id"))
+	assert.Equal(t, "generated", result.Response.Header.Get("X-Request-Id"))
+	assert.Equal(t, 200, result.Response.StatusCode)
+}
+
+func TestServeMiddleware_ShortCircuit(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/users", "")
+
+	result := httpReq.ServeMiddleware(successHandler, requestID, requireAuth)
+
+	assert.Nil(t, result.ExpectShortCircuit(401))
+	assert.Nil(t, result.NextRequest)
+	assert.Nil(t, result.Value(requestIDKey{}))
+	assert.Equal(t, "expected next handler to be called, but the middleware responded with status 401", result.ExpectNext().Error())
+	assert.Equal(t, "expected status 403, but got 401", result.ExpectShortCircuit(403).Error())
+
+	res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](result.Response)
+	assert.Nil(t, err)
+	assert.Equal(t, "missing credentials", res.Val.DebugMessage)
+}
+
+func TestServeMiddleware_Order(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/users", "").SetHeader("Authorization", "Bearer token")
+
+	result := httpReq.ServeMiddleware(successHandler, requestID, requireAuth)
+
+	assert.Nil(t, result.ExpectNext())
+	assert.Equal(t, "expected middleware to respond without calling next handler, but next handler was called", result.ExpectShortCircuit(200).Error())
+	assert.Equal(t, "generated", result.Value(requestIDKey{}))
+	assert.Equal(t, 200, result.Response.StatusCode)
+}
+
+func TestServeMiddleware_Recovery(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/users", "")
+
+	result := httpReq.ServeMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, recoverer)
+
+	assert.True(t, result.NextCalled)
+	assert.Equal(t, 500, result.Response.StatusCode)
+}
@@ -0,0 +1,112 @@
+package mockhttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NDJSONResponse is a newline-delimited JSON response, with one value per record
+type NDJSONResponse[T any] struct {
+	status         int
+	body           string
+	Vals           []T
+	validationFunc func(expected, result T) error
+}
+
+func NewNDJSONResponse[T any]() *NDJSONResponse[T] {
+	return &NDJSONResponse[T]{}
+}
+
+func (r *NDJSONResponse[T]) Status() int {
+	return r.status
+}
+
+func (r *NDJSONResponse[T]) Body() string {
+	return r.body
+}
+
+func (r *NDJSONResponse[T]) WithStatus(status int) *NDJSONResponse[T] {
+	r.status = status
+	return r
+}
+
+func (r *NDJSONResponse[T]) WithSuccess(vals []T) *NDJSONResponse[T] {
+	r.status = http.StatusOK
+	r.Vals = vals
+	return r
+}
+
+// WithValidationFunc validates each record against the expected record at the same position
+func (r *NDJSONResponse[T]) WithValidationFunc(f func(expected, result T) error) *NDJSONResponse[T] {
+	r.validationFunc = f
+	return r
+}
+
+// ToNDJSONResponse takes a httpResponse and maps it to a NDJSONResponse object
+// It parses each non-empty line into the expected type, and reports the line
+// number of the first line that can't be parsed
+func ToNDJSONResponse[T any](res *http.Response) (*NDJSONResponse[T], error) {
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &NDJSONResponse[T]{
+		status: res.StatusCode,
+		body:   string(data),
+		Vals:   []T{},
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var t T
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ret.Vals = append(ret.Vals, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Validate performs validation for two NDJSON Responses
+// When records are expected the counts must match, and the validation func,
+// if any, is run on every pair of records
+func (expected *NDJSONResponse[T]) Validate(result *NDJSONResponse[T]) error {
+	if expected == nil {
+		return errors.New("receiver expected should not be nil")
+	}
+	if result == nil {
+		return errors.New("parameter result should not be nil")
+	}
+	if expected.status != result.status {
+		return fmt.Errorf("expected status %d, but got %d", expected.status, result.status)
+	}
+	if expected.Vals == nil {
+		return nil
+	}
+	if len(expected.Vals) != len(result.Vals) {
+		return fmt.Errorf("expected %d record(s), but got %d", len(expected.Vals), len(result.Vals))
+	}
+	if expected.validationFunc == nil {
+		return nil
+	}
+	for i := range expected.Vals {
+		if err := expected.validationFunc(expected.Vals[i], result.Vals[i]); err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
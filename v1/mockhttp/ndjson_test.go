@@ -0,0 +1,87 @@
+package mockhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDJSON_RoundTrip(t *testing.T) {
+	expected := mockhttp.NewNDJSONResponse[thing]().
+		WithSuccess([]thing{{ID: 1, Name: "wax"}, {ID: 2, Name: "bee"}}).
+		WithValidationFunc(func(expected, result thing) error {
+			if expected != result {
+				return fmt.Errorf("expected %+v, but got %+v", expected, result)
+			}
+			return nil
+		})
+
+	httpReq := mockhttp.NewRequest("GET", "/export", "")
+	exportHandler(httpReq.W, httpReq.R)
+
+	res := httpReq.Result()
+	assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+	result, err := mockhttp.ToNDJSONResponse[thing](res)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"wax\"}\n{\"id\":2,\"name\":\"bee\"}\n", result.Body())
+	assert.Nil(t, expected.Validate(result))
+
+	wrong := mockhttp.NewNDJSONResponse[thing]().
+		WithSuccess([]thing{{ID: 1, Name: "wax"}, {ID: 2, Name: "wax"}}).
+		WithValidationFunc(func(expected, result thing) error {
+			if expected != result {
+				return fmt.Errorf("expected %+v, but got %+v", expected, result)
+			}
+			return nil
+		})
+	assert.Equal(t, "record 2: expected {ID:2 Name:wax}, but got {ID:2 Name:bee}", wrong.Validate(result).Error())
+
+	tooFew := mockhttp.NewNDJSONResponse[thing]().WithSuccess([]thing{{ID: 1}})
+	assert.Equal(t, "expected 1 record(s), but got 2", tooFew.Validate(result).Error())
+}
+
+func TestNDJSON_Empty(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/export", "")
+	response.NewNDJSON[thing](httpReq.W).Close()
+
+	result, err := mockhttp.ToNDJSONResponse[thing](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, result.Status())
+	assert.Len(t, result.Vals, 0)
+}
+
+func TestToNDJSONResponse_LineNumbers(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/export", "")
+	httpReq.W.WriteString("{\"id\":1}\n\n{\"id\":\"two\"}\n")
+
+	_, err := mockhttp.ToNDJSONResponse[thing](httpReq.Result())
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "line 3: json: cannot unmarshal string into Go struct field thing.id of type int", err.Error())
+}
+
+func TestNDJSON_MarshalFailure(t *testing.T) {
+	response.SetLogHandler(response.DiscardLogHandler)
+	defer response.SetLogHandler(nil)
+
+	httpReq := mockhttp.NewRequest("GET", "/export", "")
+	stream := response.NewNDJSON[interface{}](httpReq.W)
+
+	assert.NotNil(t, stream.Write(make(chan int)))
+	assert.Equal(t, 500, httpReq.Result().StatusCode)
+}
+
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	stream := response.NewNDJSON[thing](w)
+	defer stream.Close()
+	for _, t := range []thing{{ID: 1, Name: "wax"}, {ID: 2, Name: "bee"}} {
+		if err := stream.Write(t); err != nil {
+			return
+		}
+	}
+}
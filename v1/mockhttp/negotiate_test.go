@@ -0,0 +1,104 @@
+package mockhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+type thing struct {
+	ID   int    `json:"id" xml:"id" msgpack:"id" cbor:"id"`
+	Name string `json:"name" xml:"name" msgpack:"name" cbor:"name"`
+}
+
+func TestNegotiate_ContentType(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Accept      string
+		ContentType string
+	}{
+		{Name: "no_accept", Accept: "", ContentType: "application/json"},
+		{Name: "json", Accept: "application/json", ContentType: "application/json"},
+		{Name: "xml", Accept: "application/xml", ContentType: "application/xml"},
+		{Name: "msgpack", Accept: "application/msgpack", ContentType: "application/msgpack"},
+		{Name: "cbor", Accept: "application/cbor", ContentType: "application/cbor"},
+		{Name: "wildcard", Accept: "*/*", ContentType: "application/json"},
+		{Name: "q_values", Accept: "application/json;q=0.5, application/xml;q=0.9", ContentType: "application/xml"},
+		{Name: "excluded", Accept: "application/json;q=0, application/*", ContentType: "application/xml"},
+		{Name: "header_order", Accept: "application/xml, application/json", ContentType: "application/xml"},
+		{Name: "unknown_then_known", Accept: "image/png, text/xml", ContentType: "text/xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			httpReq := mockhttp.NewRequest("GET", "/things/1", "").SetHeader("Accept", tt.Accept)
+			negotiateHandler(httpReq.W, httpReq.R)
+
+			res := httpReq.Result()
+			assert.Equal(t, 200, res.StatusCode)
+			assert.Equal(t, tt.ContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, "Accept", res.Header.Get("Vary"))
+		})
+	}
+}
+
+func TestNegotiate_Decoders(t *testing.T) {
+	expected := mockhttp.NewJSONResponse[thing]().
+		WithSuccess(&thing{ID: 1, Name: "wax"}).
+		WithValidationFunc(func(expected, result thing) error {
+			if expected != result {
+				return fmt.Errorf("expected %+v, but got %+v", expected, result)
+			}
+			return nil
+		})
+
+	decoders := map[string]func(*http.Response) (*mockhttp.JSONResponse[thing], error){
+		"application/json":    mockhttp.ToJSONResponse[thing],
+		"application/xml":     mockhttp.ToXMLResponse[thing],
+		"application/msgpack": mockhttp.ToMsgPackResponse[thing],
+		"application/cbor":    mockhttp.ToCBORResponse[thing],
+	}
+	for accept, decode := range decoders {
+		t.Run(accept, func(t *testing.T) {
+			httpReq := mockhttp.NewRequest("GET", "/things/1", "").SetHeader("Accept", accept)
+			negotiateHandler(httpReq.W, httpReq.R)
+
+			result, err := decode(httpReq.Result())
+			assert.Nil(t, err)
+			assert.Nil(t, expected.Validate(result))
+		})
+	}
+}
+
+func TestNegotiate_NotAcceptable(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/things/1", "").SetHeader("Accept", "image/png")
+	negotiateHandler(httpReq.W, httpReq.R)
+
+	res, err := mockhttp.ToJSONResponse[mockhttp.ServerError](httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, 406, res.Status())
+	assert.Equal(t, "not acceptable", res.Val.Status)
+	assert.Equal(t, "no acceptable media type for image/png", res.Val.DebugMessage)
+}
+
+func TestNegotiate_RegisterEncoder(t *testing.T) {
+	response.RegisterEncoder("text/csv", func(body interface{}) ([]byte, error) {
+		t := body.(thing)
+		return []byte(fmt.Sprintf("%d,%s\n", t.ID, t.Name)), nil
+	})
+
+	httpReq := mockhttp.NewRequest("GET", "/things/1", "").SetHeader("Accept", "text/csv")
+	negotiateHandler(httpReq.W, httpReq.R)
+
+	res, err := mockhttp.ToResponse(httpReq.Result())
+	assert.Nil(t, err)
+	assert.Equal(t, "1,wax\n", res.Body())
+}
+
+func negotiateHandler(w http.ResponseWriter, r *http.Request) {
+	response.Negotiate(w, r, http.StatusOK, thing{ID: 1, Name: "wax"})
+}
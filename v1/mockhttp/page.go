@@ -0,0 +1,91 @@
+package mockhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sachsry/mockhttp/v1/response"
+)
+
+// PageWalk is the result of WalkPages
+type PageWalk[T any] struct {
+	Pages []*JSONResponse[response.Page[T]]
+	// Items is the union of the items of every page, in order
+	Items []T
+}
+
+// WalkPages sends first to handler, then keeps requesting the next page until
+// there is none, and returns every page it saw. The next page comes from the
+// rel="next" Link header, or from the page's Next cursor when there is no header.
+// Follow-up requests keep the method, headers and context of first.
+// It returns an error if a page isn't a 200, a page is requested twice,
+// or there are more than maxPages pages. A maxPages of 0 or less means no limit
+func WalkPages[T any](handler http.HandlerFunc, first *Request, maxPages int) (*PageWalk[T], error) {
+	walk := &PageWalk[T]{}
+	seen := map[string]bool{}
+	req := first
+	for {
+		uri := req.R.URL.RequestURI()
+		if seen[uri] {
+			return walk, fmt.Errorf("page %s was requested twice", uri)
+		}
+		seen[uri] = true
+		if maxPages > 0 && len(walk.Pages) == maxPages {
+			return walk, fmt.Errorf("expected at most %d pages, but there are more", maxPages)
+		}
+
+		handler(req.W, req.R)
+		res := req.Result()
+		page, err := ToJSONResponse[response.Page[T]](res)
+		if err != nil {
+			return walk, fmt.Errorf("page %s: %w", uri, err)
+		}
+		if page.Status() != http.StatusOK {
+			return walk, fmt.Errorf("page %s: expected status %d, but got %d", uri, http.StatusOK, page.Status())
+		}
+		walk.Pages = append(walk.Pages, page)
+		walk.Items = append(walk.Items, page.Val.Items...)
+
+		next := nextPage(req.R.URL, page)
+		if next == "" {
+			return walk, nil
+		}
+		req = NewRequest(first.R.Method, next, "").WithContext(first.Context())
+		req.R.Header = first.R.Header.Clone()
+	}
+}
+
+func nextPage[T any](current *url.URL, page *JSONResponse[response.Page[T]]) string {
+	if next, ok := ParseLinks(page.Header().Get("Link"))["next"]; ok {
+		return next
+	}
+	if page.Val.Next == "" {
+		return ""
+	}
+	q := current.Query()
+	q.Set(response.CursorParam, page.Val.Next)
+	return current.Path + "?" + q.Encode()
+}
+
+// ParseLinks parses an RFC 8288 Link header into a map of rel to URL
+func ParseLinks(header string) map[string]string {
+	links := map[string]string{}
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+		if target == "" {
+			continue
+		}
+		for _, param := range parts[1:] {
+			key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "rel") {
+				for _, rel := range strings.Fields(strings.Trim(val, `"`)) {
+					links[rel] = target
+				}
+			}
+		}
+	}
+	return links
+}
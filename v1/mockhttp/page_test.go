@@ -0,0 +1,107 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+var allThings = []int{1, 2, 3, 4, 5, 6, 7}
+
+func TestWritePage_LinkHeader(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/things?page=2&limit=3&sort=id", "")
+	numberedPageHandler(httpReq.W, httpReq.R)
+
+	links := mockhttp.ParseLinks(httpReq.Result().Header.Get("Link"))
+	assert.Equal(t, map[string]string{
+		"next":  "/things?limit=3&page=3&sort=id",
+		"prev":  "/things?limit=3&page=1&sort=id",
+		"first": "/things?limit=3&page=1&sort=id",
+		"last":  "/things?limit=3&page=3&sort=id",
+	}, links)
+}
+
+func TestWalkPages_Cursor(t *testing.T) {
+	walk, err := mockhttp.WalkPages[int](cursorPageHandler,
+		mockhttp.NewRequest("GET", "/things?limit=3", "").SetHeader("Authorization", "Bearer abc"), 10)
+
+	assert.Nil(t, err)
+	assert.Len(t, walk.Pages, 3)
+	assert.Equal(t, allThings, walk.Items)
+}
+
+func TestWalkPages_Numbered(t *testing.T) {
+	walk, err := mockhttp.WalkPages[int](numberedPageHandler, mockhttp.NewRequest("GET", "/things?page=1&limit=2", ""), 10)
+
+	assert.Nil(t, err)
+	assert.Len(t, walk.Pages, 4)
+	assert.Equal(t, allThings, walk.Items)
+	assert.Equal(t, 7, walk.Pages[0].Val.Total)
+}
+
+func TestWalkPages_NoLimit(t *testing.T) {
+	walk, err := mockhttp.WalkPages[int](numberedPageHandler, mockhttp.NewRequest("GET", "/things?page=1&limit=2", ""), 0)
+
+	assert.Nil(t, err)
+	assert.Len(t, walk.Pages, 4)
+	assert.Equal(t, allThings, walk.Items)
+}
+
+func TestWalkPages_Errors(t *testing.T) {
+	_, err := mockhttp.WalkPages[int](cursorPageHandler, mockhttp.NewRequest("GET", "/things?limit=1", "").SetHeader("Authorization", "Bearer abc"), 3)
+	assert.Equal(t, "expected at most 3 pages, but there are more", err.Error())
+
+	_, err = mockhttp.WalkPages[int](cursorPageHandler, mockhttp.NewRequest("GET", "/things?limit=1", ""), 3)
+	assert.Equal(t, "page /things?limit=1: expected status 200, but got 401", err.Error())
+
+	loop := func(w http.ResponseWriter, r *http.Request) {
+		response.WritePage(w, r, response.Page[int]{Items: []int{1}, Next: "same"})
+	}
+	walk, err := mockhttp.WalkPages[int](loop, mockhttp.NewRequest("GET", "/things", ""), 10)
+	assert.Equal(t, "page /things?cursor=same was requested twice", err.Error())
+	assert.Equal(t, []int{1, 1}, walk.Items)
+}
+
+// cursorPageHandler pages through allThings using the index of the next item as the cursor,
+// and requires the Authorization header to show that headers are carried between pages
+func cursorPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		response.Error(w, 401, "", nil)
+		return
+	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	end := start + limit
+	if end > len(allThings) {
+		end = len(allThings)
+	}
+
+	page := response.Page[int]{Items: allThings[start:end], Total: len(allThings), Limit: limit}
+	if end < len(allThings) {
+		page.Next = strconv.Itoa(end)
+	}
+	if start > 0 {
+		page.Prev = strconv.Itoa(start - limit)
+	}
+	response.WritePage(w, r, page)
+}
+
+func numberedPageHandler(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	start := (page - 1) * limit
+	end := start + limit
+	if end > len(allThings) {
+		end = len(allThings)
+	}
+	response.WritePage(w, r, response.Page[int]{
+		Items: allThings[start:end],
+		Total: len(allThings),
+		Page:  page,
+		Limit: limit,
+	})
+}
@@ -0,0 +1,81 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ProblemDetails is an RFC 9457 (formerly RFC 7807) problem details response.
+// Members other than the standard ones are collected in Extensions
+type ProblemDetails struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	// alias drops the UnmarshalJSON method so the standard members decode normally
+	type alias ProblemDetails
+	var standard alias
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+	*p = ProblemDetails(standard)
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// ValidateProblem compares the standard members and every extension in expected.
+// Extensions only present in result are ignored
+func ValidateProblem(expected, result ProblemDetails) error {
+	if expected.Type != result.Type {
+		return fmt.Errorf("expected type: %s, but got %s", expected.Type, result.Type)
+	}
+	if expected.Title != result.Title {
+		return fmt.Errorf("expected title: %s, but got %s", expected.Title, result.Title)
+	}
+	if expected.Status != result.Status {
+		return fmt.Errorf("expected status: %d, but got %d", expected.Status, result.Status)
+	}
+	if expected.Detail != result.Detail {
+		return fmt.Errorf("expected detail: %s, but got %s", expected.Detail, result.Detail)
+	}
+	if expected.Instance != result.Instance {
+		return fmt.Errorf("expected instance: %s, but got %s", expected.Instance, result.Instance)
+	}
+
+	keys := make([]string, 0, len(expected.Extensions))
+	for key := range expected.Extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// round trip through JSON so that e.g. an int matches the decoded float64
+		var want interface{}
+		if err := json.Unmarshal([]byte(toJSON(expected.Extensions[key])), &want); err != nil {
+			return fmt.Errorf("unable to compare extension %s: %v", key, err)
+		}
+		got, ok := result.Extensions[key]
+		if !ok {
+			return fmt.Errorf("expected extension %s: %s, but it was missing", key, toJSON(want))
+		}
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Errorf("expected extension %s: %s, but got %s", key, toJSON(want), toJSON(got))
+		}
+	}
+	return nil
+}
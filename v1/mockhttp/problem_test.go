@@ -0,0 +1,83 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_Defaults(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	response.Problem(httpReq.W, response.ProblemDetails{Status: 404})
+
+	res := httpReq.Result()
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+	raw, err := mockhttp.ToResponse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, raw.Status())
+	assert.Equal(t, `{"status":404,"title":"Not Found","type":"about:blank"}`, raw.Body())
+}
+
+func TestProblem_Validate(t *testing.T) {
+	expected := mockhttp.NewJSONResponse[mockhttp.ProblemDetails]().
+		WithFailure(403, &mockhttp.ProblemDetails{
+			Type:     "https://example.com/probs/out-of-credit",
+			Title:    "You do not have enough credit.",
+			Status:   403,
+			Detail:   "Your current balance is 30, but that costs 50.",
+			Instance: "/account/12345/msgs/abc",
+			Extensions: map[string]interface{}{
+				"balance":  30,
+				"accounts": []string{"/account/12345"},
+			},
+		}).
+		WithValidationFunc(mockhttp.ValidateProblem)
+
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	problemHandler(httpReq.W, httpReq.R)
+	result, err := mockhttp.ToJSONResponse[mockhttp.ProblemDetails](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", result.Val.Extensions["trace"])
+	assert.Nil(t, expected.Validate(result))
+}
+
+func TestValidateProblem_Errors(t *testing.T) {
+	result := mockhttp.ProblemDetails{
+		Status:     400,
+		Extensions: map[string]interface{}{"balance": float64(30)},
+	}
+
+	err := mockhttp.ValidateProblem(mockhttp.ProblemDetails{Status: 409}, result)
+	assert.Equal(t, "expected status: 409, but got 400", err.Error())
+
+	err = mockhttp.ValidateProblem(mockhttp.ProblemDetails{
+		Status:     400,
+		Extensions: map[string]interface{}{"balance": 40},
+	}, result)
+	assert.Equal(t, "expected extension balance: 40, but got 30", err.Error())
+
+	err = mockhttp.ValidateProblem(mockhttp.ProblemDetails{
+		Status:     400,
+		Extensions: map[string]interface{}{"limit": 10},
+	}, result)
+	assert.Equal(t, "expected extension limit: 10, but it was missing", err.Error())
+}
+
+func problemHandler(w http.ResponseWriter, r *http.Request) {
+	response.Problem(w, response.ProblemDetails{
+		Type:     "https://example.com/probs/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Status:   403,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Extensions: map[string]interface{}{
+			"balance":  30,
+			"accounts": []string{"/account/12345"},
+			"trace":    "abc",
+		},
+	})
+}
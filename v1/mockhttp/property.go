@@ -0,0 +1,709 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DefaultPropertyRuns is the number of inputs a PropertyTest generates
+const DefaultPropertyRuns = 100
+
+// maxShrinkAttempts bounds how many candidates are tried while shrinking
+const maxShrinkAttempts = 2000
+
+// PropertyTest sends JSON bodies generated from T to a handler and checks
+// properties against every response. Fields are generated according to their
+// gen struct tag:
+//
+//	gen:"min=1,max=10"       numbers, or the elements of a slice, between min and max
+//	gen:"oneof=red|green"    one of the listed values
+//	gen:"minlen=1,maxlen=5"  length of a string, slice or map
+//	gen:"-"                  left as the zero value
+//
+// Run returns an error for a min, max or oneof value the field's type can't hold.
+// A failing input is shrunk to a minimal one before it is reported
+type PropertyTest[T any] struct {
+	Method string
+	Path   string
+	Runs   int
+	Seed   int64
+
+	properties []FuzzProperty
+}
+
+// NewPropertyTest returns a PropertyTest that checks for panics, 5xx
+// statuses and invalid JSON, seeded from the current time
+func NewPropertyTest[T any](method, path string) *PropertyTest[T] {
+	return &PropertyTest[T]{
+		Method:     method,
+		Path:       path,
+		Runs:       DefaultPropertyRuns,
+		Seed:       time.Now().UnixNano(),
+		properties: []FuzzProperty{No5xx(), ValidJSON()},
+	}
+}
+
+// WithRuns sets the number of generated inputs
+func (p *PropertyTest[T]) WithRuns(n int) *PropertyTest[T] {
+	p.Runs = n
+	return p
+}
+
+// WithSeed makes the generated inputs reproducible
+func (p *PropertyTest[T]) WithSeed(seed int64) *PropertyTest[T] {
+	p.Seed = seed
+	return p
+}
+
+// WithProperties adds properties to check on every response
+func (p *PropertyTest[T]) WithProperties(props ...FuzzProperty) *PropertyTest[T] {
+	p.properties = append(p.properties, props...)
+	return p
+}
+
+// PropertyFailure is returned by PropertyTest.Run when an input fails
+type PropertyFailure struct {
+	Seed int64
+	// Run is the 1-based index of the first failing input
+	Run int
+	// Shrinks is how many times the input was simplified
+	Shrinks int
+	// Body is the minimal failing request body
+	Body string
+	// Case is a ready-to-paste TestStruct for the minimal input
+	Case string
+	Err  error
+}
+
+func (f *PropertyFailure) Error() string {
+	return fmt.Sprintf("property failed on input %d (seed %d), shrunk in %d steps: %v\nminimal failing case:\n%s",
+		f.Run, f.Seed, f.Shrinks, f.Err, f.Case)
+}
+
+func (f *PropertyFailure) Unwrap() error {
+	return f.Err
+}
+
+// Check runs the test and fails t with the minimal failing case
+func (p *PropertyTest[T]) Check(t testing.TB, handler http.HandlerFunc) {
+	t.Helper()
+	if err := p.Run(handler); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Run sends Runs generated bodies to handler. It returns a *PropertyFailure
+// for the first failing input, shrunk to a minimal case
+func (p *PropertyTest[T]) Run(handler http.HandlerFunc) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if err := validateGenTags(typ, 0); err != nil {
+		return err
+	}
+	rng := rand.New(rand.NewSource(p.Seed))
+	for i := 1; i <= p.Runs; i++ {
+		val := generate(rng, typ, genRule{}, 0)
+		if err := p.check(handler, val); err != nil {
+			minimal, minErr, shrinks := p.shrink(handler, val, err)
+			body, _ := json.Marshal(minimal.Interface())
+			return &PropertyFailure{
+				Seed:    p.Seed,
+				Run:     i,
+				Shrinks: shrinks,
+				Body:    string(body),
+				Case:    p.testCase(string(body)),
+				Err:     minErr,
+			}
+		}
+	}
+	return nil
+}
+
+func (p *PropertyTest[T]) check(handler http.HandlerFunc, val reflect.Value) error {
+	body, err := json.Marshal(val.Interface())
+	if err != nil {
+		return err
+	}
+	req := NewRequest(p.Method, p.Path, string(body)).SetHeader("Content-Type", "application/json")
+	return checkProperties(handler, req, p.properties)
+}
+
+// shrink greedily replaces val with the first simpler candidate that still
+// fails, until no candidate fails
+func (p *PropertyTest[T]) shrink(handler http.HandlerFunc, val reflect.Value, err error) (reflect.Value, error, int) {
+	shrinks, attempts := 0, 0
+	for attempts < maxShrinkAttempts {
+		improved := false
+		for _, candidate := range shrinkValue(val, genRule{}, 0) {
+			attempts++
+			if candidateErr := p.check(handler, candidate); candidateErr != nil {
+				val, err = candidate, candidateErr
+				shrinks++
+				improved = true
+				break
+			}
+			if attempts >= maxShrinkAttempts {
+				break
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return val, err, shrinks
+}
+
+func (p *PropertyTest[T]) testCase(body string) string {
+	quoted := "`" + body + "`"
+	if strings.Contains(body, "`") {
+		quoted = strconv.Quote(body)
+	}
+	return fmt.Sprintf("{\n\tName: %q,\n\tInput: mockhttp.NewRequest(%q, %q, %s).\n\t\tSetHeader(\"Content-Type\", \"application/json\"),\n},",
+		fmt.Sprintf("property_seed_%d", p.Seed), p.Method, p.Path, quoted)
+}
+
+// Generate returns a random value of T that follows its gen struct tags
+func Generate[T any](rng *rand.Rand) T {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return generate(rng, typ, genRule{}, 0).Interface().(T)
+}
+
+type genRule struct {
+	skip           bool
+	min, max       *float64
+	minLen, maxLen *int
+	oneOf          []string
+}
+
+// elem returns the rule for the elements of a slice or map, which keep the
+// value constraints but not the length ones
+func (r genRule) elem() genRule {
+	return genRule{min: r.min, max: r.max, oneOf: r.oneOf}
+}
+
+func (r genRule) bounds(lo, hi float64) (float64, float64) {
+	if r.min != nil {
+		lo = *r.min
+	}
+	if r.max != nil {
+		hi = *r.max
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+func (r genRule) lengths(lo, hi int) (int, int) {
+	if r.minLen != nil {
+		lo = *r.minLen
+	}
+	if r.maxLen != nil {
+		hi = *r.maxLen
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+func parseGenTag(tag string) (genRule, error) {
+	var rule genRule
+	if tag == "-" {
+		rule.skip = true
+		return rule, nil
+	}
+	for _, part := range strings.Split(tag, ",") {
+		if part == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(part, "=")
+		switch key {
+		case "min", "max":
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return rule, fmt.Errorf("expected a number for gen tag %s, but got %q", key, val)
+			}
+			if key == "min" {
+				rule.min = &f
+			} else {
+				rule.max = &f
+			}
+		case "minlen", "maxlen":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return rule, fmt.Errorf("expected a non-negative integer for gen tag %s, but got %q", key, val)
+			}
+			if key == "minlen" {
+				rule.minLen = &n
+			} else {
+				rule.maxLen = &n
+			}
+		case "oneof":
+			rule.oneOf = strings.Split(val, "|")
+		default:
+			return rule, fmt.Errorf("unknown gen tag option %q", key)
+		}
+	}
+	return rule, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func validateGenTags(typ reflect.Type, depth int) error {
+	if depth > 5 {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return validateGenTags(typ.Elem(), depth+1)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			rule, err := parseGenTag(field.Tag.Get("gen"))
+			if err == nil {
+				err = checkGenBounds(rule, field.Type)
+			}
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", typ.Name(), field.Name, err)
+			}
+			if err := validateGenTags(field.Type, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkGenBounds reports min, max and oneof values that don't fit the kind of
+// typ, or of its elements for a pointer, slice, array or map
+func checkGenBounds(rule genRule, typ reflect.Type) error {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return nil
+	}
+	kind := typ.Kind()
+	lo, hi, ok := kindRange(typ)
+	if !ok {
+		return nil
+	}
+	for i, bound := range []*float64{rule.min, rule.max} {
+		if bound == nil {
+			continue
+		}
+		key, b := "min", *bound
+		if i == 1 {
+			key = "max"
+		}
+		fits := !math.IsNaN(b) && b >= lo && b <= hi
+		if kind != reflect.Float32 && kind != reflect.Float64 {
+			fits = fits && b == math.Trunc(b) && b < hi
+		}
+		if !fits {
+			return fmt.Errorf("expected gen tag %s to fit in %s, but got %v", key, kind, b)
+		}
+	}
+	for _, val := range rule.oneOf {
+		var err error
+		switch kind {
+		case reflect.Float32, reflect.Float64:
+			_, err = strconv.ParseFloat(val, typ.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, err = strconv.ParseUint(val, 10, typ.Bits())
+		default:
+			_, err = strconv.ParseInt(val, 10, typ.Bits())
+		}
+		if err != nil {
+			return fmt.Errorf("expected gen tag oneof values to fit in %s, but got %q", kind, val)
+		}
+	}
+	return nil
+}
+
+// kindRange returns the range of a numeric type. For integers, hi itself is
+// one past the largest value, since that is exact as a float64
+func kindRange(typ reflect.Type) (lo, hi float64, ok bool) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return -math.Ldexp(1, typ.Bits()-1), math.Ldexp(1, typ.Bits()-1), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, math.Ldexp(1, typ.Bits()), true
+	case reflect.Float32:
+		return -math.MaxFloat32, math.MaxFloat32, true
+	case reflect.Float64:
+		return -math.MaxFloat64, math.MaxFloat64, true
+	}
+	return 0, 0, false
+}
+
+const genAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_"
+
+// generate builds a random value of typ. Recursive types stop at depth 5
+func generate(rng *rand.Rand, typ reflect.Type, rule genRule, depth int) reflect.Value {
+	val := reflect.New(typ).Elem()
+	if rule.skip || depth > 5 {
+		return val
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		val.SetBool(rng.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if len(rule.oneOf) > 0 {
+			n, _ := strconv.ParseInt(rule.oneOf[rng.Intn(len(rule.oneOf))], 10, 64)
+			val.SetInt(n)
+			break
+		}
+		lo, hi := intBounds(rule, typ, -100, 100)
+		val.SetInt(randInt(rng, lo, hi))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(rule.oneOf) > 0 {
+			n, _ := strconv.ParseUint(rule.oneOf[rng.Intn(len(rule.oneOf))], 10, 64)
+			val.SetUint(n)
+			break
+		}
+		lo, hi := uintBounds(rule, typ, 0, 100)
+		val.SetUint(randUint(rng, lo, hi))
+	case reflect.Float32, reflect.Float64:
+		if len(rule.oneOf) > 0 {
+			f, _ := strconv.ParseFloat(rule.oneOf[rng.Intn(len(rule.oneOf))], 64)
+			val.SetFloat(f)
+			break
+		}
+		lo, hi := rule.bounds(-100, 100)
+		// Weighting the bounds, unlike lo+f*(hi-lo), can't overflow for wide ranges
+		f := rng.Float64()
+		val.SetFloat((1-f)*lo + f*hi)
+	case reflect.String:
+		if len(rule.oneOf) > 0 {
+			val.SetString(rule.oneOf[rng.Intn(len(rule.oneOf))])
+			break
+		}
+		lo, hi := rule.lengths(0, 16)
+		b := make([]byte, lo+rng.Intn(hi-lo+1))
+		for i := range b {
+			b[i] = genAlphabet[rng.Intn(len(genAlphabet))]
+		}
+		val.SetString(string(b))
+	case reflect.Slice:
+		lo, hi := rule.lengths(0, 5)
+		n := lo + rng.Intn(hi-lo+1)
+		slice := reflect.MakeSlice(typ, n, n)
+		for i := 0; i < n; i++ {
+			slice.Index(i).Set(generate(rng, typ.Elem(), rule.elem(), depth+1))
+		}
+		val.Set(slice)
+	case reflect.Array:
+		for i := 0; i < typ.Len(); i++ {
+			val.Index(i).Set(generate(rng, typ.Elem(), rule.elem(), depth+1))
+		}
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			break
+		}
+		lo, hi := rule.lengths(0, 3)
+		n := lo + rng.Intn(hi-lo+1)
+		m := reflect.MakeMapWithSize(typ, n)
+		for m.Len() < n {
+			key := generate(rng, typ.Key(), genRule{minLen: intPtr(1), maxLen: intPtr(8)}, depth+1)
+			m.SetMapIndex(key, generate(rng, typ.Elem(), rule.elem(), depth+1))
+		}
+		val.Set(m)
+	case reflect.Ptr:
+		if rng.Intn(4) == 0 {
+			break
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(generate(rng, typ.Elem(), rule, depth+1))
+		val.Set(ptr)
+	case reflect.Struct:
+		if typ == timeType {
+			val.Set(reflect.ValueOf(time.Unix(946684800+rng.Int63n(30*365*24*3600), 0).UTC()))
+			break
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			fieldRule, _ := parseGenTag(field.Tag.Get("gen"))
+			val.Field(i).Set(generate(rng, field.Type, fieldRule, depth+1))
+		}
+	}
+	return val
+}
+
+// shrinkValue returns simpler values than val that still follow rule, most
+// aggressive first
+func shrinkValue(val reflect.Value, rule genRule, depth int) []reflect.Value {
+	if rule.skip || depth > 5 {
+		return nil
+	}
+	typ := val.Type()
+	var candidates []reflect.Value
+	add := func(set func(v reflect.Value)) {
+		c := reflect.New(typ).Elem()
+		c.Set(val)
+		set(c)
+		candidates = append(candidates, c)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if val.Bool() {
+			add(func(v reflect.Value) { v.SetBool(false) })
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := val.Int()
+		if len(rule.oneOf) > 0 {
+			first, _ := strconv.ParseInt(rule.oneOf[0], 10, 64)
+			if n != first {
+				add(func(v reflect.Value) { v.SetInt(first) })
+			}
+			break
+		}
+		lo, hi := intBounds(rule, typ, -100, 100)
+		target := max(lo, min(0, hi))
+		for _, c := range shrinkTowards(n, target) {
+			c := c
+			add(func(v reflect.Value) { v.SetInt(c) })
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := val.Uint()
+		if len(rule.oneOf) > 0 {
+			first, _ := strconv.ParseUint(rule.oneOf[0], 10, 64)
+			if n != first {
+				add(func(v reflect.Value) { v.SetUint(first) })
+			}
+			break
+		}
+		lo, _ := uintBounds(rule, typ, 0, 100)
+		for _, c := range shrinkTowards(n, lo) {
+			c := c
+			add(func(v reflect.Value) { v.SetUint(c) })
+		}
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if len(rule.oneOf) > 0 {
+			first, _ := strconv.ParseFloat(rule.oneOf[0], 64)
+			if f != first {
+				add(func(v reflect.Value) { v.SetFloat(first) })
+			}
+			break
+		}
+		lo, hi := rule.bounds(-100, 100)
+		target := math.Max(lo, math.Min(0, hi))
+		if f != target {
+			add(func(v reflect.Value) { v.SetFloat(target) })
+		}
+		if trunc := math.Trunc(f); trunc != f && trunc >= lo && trunc <= hi {
+			add(func(v reflect.Value) { v.SetFloat(trunc) })
+		}
+	case reflect.String:
+		s := val.String()
+		if len(rule.oneOf) > 0 {
+			if s != rule.oneOf[0] {
+				add(func(v reflect.Value) { v.SetString(rule.oneOf[0]) })
+			}
+			break
+		}
+		lo, _ := rule.lengths(0, 16)
+		if len(s) > lo {
+			add(func(v reflect.Value) { v.SetString(s[:lo]) })
+			if half := len(s) / 2; half > lo {
+				add(func(v reflect.Value) { v.SetString(s[:half]) })
+			}
+			if len(s)-1 > lo {
+				add(func(v reflect.Value) { v.SetString(s[1:]) })
+				add(func(v reflect.Value) { v.SetString(s[:len(s)-1]) })
+			}
+		}
+		if i := strings.IndexFunc(s, func(r rune) bool { return r != 'a' }); i >= 0 {
+			add(func(v reflect.Value) { v.SetString(s[:i] + "a" + s[i+1:]) })
+		}
+	case reflect.Slice:
+		lo, _ := rule.lengths(0, 5)
+		n := val.Len()
+		if n > lo {
+			add(func(v reflect.Value) { v.Set(val.Slice(0, lo)) })
+			for i := 0; i < n; i++ {
+				i := i
+				add(func(v reflect.Value) {
+					s := reflect.MakeSlice(typ, 0, n-1)
+					s = reflect.AppendSlice(s, val.Slice(0, i))
+					v.Set(reflect.AppendSlice(s, val.Slice(i+1, n)))
+				})
+			}
+		}
+		for i := 0; i < n; i++ {
+			for _, elem := range shrinkValue(val.Index(i), rule.elem(), depth+1) {
+				i, elem := i, elem
+				add(func(v reflect.Value) {
+					s := reflect.MakeSlice(typ, n, n)
+					reflect.Copy(s, val)
+					s.Index(i).Set(elem)
+					v.Set(s)
+				})
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			for _, elem := range shrinkValue(val.Index(i), rule.elem(), depth+1) {
+				i, elem := i, elem
+				add(func(v reflect.Value) { v.Index(i).Set(elem) })
+			}
+		}
+	case reflect.Map:
+		if val.IsNil() {
+			break
+		}
+		lo, _ := rule.lengths(0, 3)
+		keys := val.MapKeys()
+		for _, key := range keys {
+			key := key
+			if len(keys) > lo {
+				add(func(v reflect.Value) {
+					m := copyMap(val)
+					m.SetMapIndex(key, reflect.Value{})
+					v.Set(m)
+				})
+			}
+			for _, elem := range shrinkValue(val.MapIndex(key), rule.elem(), depth+1) {
+				elem := elem
+				add(func(v reflect.Value) {
+					m := copyMap(val)
+					m.SetMapIndex(key, elem)
+					v.Set(m)
+				})
+			}
+		}
+	case reflect.Ptr:
+		if val.IsNil() {
+			break
+		}
+		add(func(v reflect.Value) { v.Set(reflect.Zero(typ)) })
+		for _, elem := range shrinkValue(val.Elem(), rule, depth+1) {
+			elem := elem
+			add(func(v reflect.Value) {
+				ptr := reflect.New(typ.Elem())
+				ptr.Elem().Set(elem)
+				v.Set(ptr)
+			})
+		}
+	case reflect.Struct:
+		if typ == timeType {
+			epoch := time.Unix(946684800, 0).UTC()
+			if !val.Interface().(time.Time).Equal(epoch) {
+				add(func(v reflect.Value) { v.Set(reflect.ValueOf(epoch)) })
+			}
+			break
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			fieldRule, _ := parseGenTag(field.Tag.Get("gen"))
+			for _, c := range shrinkValue(val.Field(i), fieldRule, depth+1) {
+				i, c := i, c
+				add(func(v reflect.Value) { v.Field(i).Set(c) })
+			}
+		}
+	}
+	return candidates
+}
+
+// shrinkTowards returns target, the midpoint and the neighbour of n on the
+// way to target
+func shrinkTowards[N int64 | uint64](n, target N) []N {
+	if n == target {
+		return nil
+	}
+	candidates := []N{target}
+	mid, next := n-(n-target)/2, n-1
+	if n < target {
+		mid, next = n+(target-n)/2, n+1
+	}
+	if mid != n && mid != target {
+		candidates = append(candidates, mid)
+	}
+	if next != target {
+		candidates = append(candidates, next)
+	}
+	return candidates
+}
+
+// intBounds returns the rule's bounds for a signed integer type, clamped to
+// what the type can hold
+func intBounds(rule genRule, typ reflect.Type, lo, hi float64) (int64, int64) {
+	lo, hi = rule.bounds(lo, hi)
+	floor, end, _ := kindRange(typ)
+	top := int64(math.MaxInt64 >> (64 - typ.Bits()))
+	clamp := func(f float64) int64 {
+		switch {
+		case f >= end:
+			return top
+		case f < floor:
+			return int64(floor)
+		}
+		return int64(f)
+	}
+	return clamp(lo), clamp(hi)
+}
+
+// uintBounds is intBounds for unsigned integer types
+func uintBounds(rule genRule, typ reflect.Type, lo, hi float64) (uint64, uint64) {
+	lo, hi = rule.bounds(lo, hi)
+	_, end, _ := kindRange(typ)
+	top := uint64(math.MaxUint64 >> (64 - typ.Bits()))
+	clamp := func(f float64) uint64 {
+		switch {
+		case f >= end:
+			return top
+		case f < 0:
+			return 0
+		}
+		return uint64(f)
+	}
+	return clamp(lo), clamp(hi)
+}
+
+// randInt returns a random number in [lo, hi], even when the range is wider
+// than rand.Int63n allows
+func randInt(rng *rand.Rand, lo, hi int64) int64 {
+	return lo + int64(randUint(rng, 0, uint64(hi)-uint64(lo)))
+}
+
+// randUint returns a random number in [lo, hi]
+func randUint(rng *rand.Rand, lo, hi uint64) uint64 {
+	span := hi - lo
+	if span < math.MaxInt64 {
+		return lo + uint64(rng.Int63n(int64(span)+1))
+	}
+	for {
+		if n := rng.Uint64(); n <= span {
+			return lo + n
+		}
+	}
+}
+
+func copyMap(m reflect.Value) reflect.Value {
+	c := reflect.MakeMapWithSize(m.Type(), m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		c.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return c
+}
+
+func intPtr(n int) *int {
+	return &n
+}
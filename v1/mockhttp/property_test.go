@@ -0,0 +1,146 @@
+package mockhttp_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	Name     string   `json:"name" gen:"minlen=2,maxlen=10"`
+	Status   string   `json:"status" gen:"oneof=pending|paid|shipped"`
+	Quantity int      `json:"quantity" gen:"min=1,max=100"`
+	Price    float64  `json:"price" gen:"min=0,max=1000"`
+	Tags     []string `json:"tags" gen:"maxlen=3"`
+	Note     *string  `json:"note"`
+	Internal string   `json:"-"`
+}
+
+func handleCreateOrder(maxQuantity int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := response.DecodeJSON[order](w, r, nil)
+		if err != nil {
+			return
+		}
+		if body.Quantity > maxQuantity {
+			response.Error(w, http.StatusInternalServerError, "not enough stock", nil)
+			return
+		}
+		response.Created(w, "/orders/1", body)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	statuses := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		o := mockhttp.Generate[order](rng)
+		assert.GreaterOrEqual(t, len(o.Name), 2)
+		assert.LessOrEqual(t, len(o.Name), 10)
+		assert.Contains(t, []string{"pending", "paid", "shipped"}, o.Status)
+		assert.GreaterOrEqual(t, o.Quantity, 1)
+		assert.LessOrEqual(t, o.Quantity, 100)
+		assert.GreaterOrEqual(t, o.Price, 0.0)
+		assert.LessOrEqual(t, o.Price, 1000.0)
+		assert.LessOrEqual(t, len(o.Tags), 3)
+		assert.Empty(t, o.Internal)
+		statuses[o.Status] = true
+	}
+	assert.Len(t, statuses, 3)
+}
+
+func TestPropertyTest_Passes(t *testing.T) {
+	mockhttp.NewPropertyTest[order]("POST", "/orders").
+		WithSeed(1).
+		WithProperties(mockhttp.ConformsTo[order](201)).
+		Check(t, handleCreateOrder(100))
+}
+
+func TestPropertyTest_Shrinks(t *testing.T) {
+	err := mockhttp.NewPropertyTest[order]("POST", "/orders").
+		WithSeed(1).
+		Run(handleCreateOrder(20))
+
+	var failure *mockhttp.PropertyFailure
+	assert.True(t, errors.As(err, &failure))
+	assert.Equal(t, `{"name":"aa","status":"pending","quantity":21,"price":0,"tags":[],"note":null}`, failure.Body)
+	assert.Equal(t, `expected a status below 500, but got 500: {"message":"not enough stock","status":"internal error"}`, failure.Err.Error())
+	assert.Equal(t, "{\n"+
+		"\tName: \"property_seed_1\",\n"+
+		"\tInput: mockhttp.NewRequest(\"POST\", \"/orders\", `"+failure.Body+"`).\n"+
+		"\t\tSetHeader(\"Content-Type\", \"application/json\"),\n"+
+		"},", failure.Case)
+}
+
+func TestPropertyTest_InvalidTag(t *testing.T) {
+	type bad struct {
+		Count int `gen:"min=one"`
+	}
+
+	err := mockhttp.NewPropertyTest[bad]("POST", "/").Run(successHandler)
+
+	assert.EqualError(t, err, `bad.Count: expected a number for gen tag min, but got "one"`)
+}
+
+func TestPropertyTest_BoundsMustFitKind(t *testing.T) {
+	type small struct {
+		Count int8 `gen:"max=1000"`
+	}
+	type negative struct {
+		Count []uint `gen:"min=-1"`
+	}
+	type fraction struct {
+		Count int `gen:"min=0.5"`
+	}
+	type choice struct {
+		Count *uint8 `gen:"oneof=1|300"`
+	}
+
+	err := mockhttp.NewPropertyTest[small]("POST", "/").Run(successHandler)
+	assert.EqualError(t, err, "small.Count: expected gen tag max to fit in int8, but got 1000")
+	err = mockhttp.NewPropertyTest[negative]("POST", "/").Run(successHandler)
+	assert.EqualError(t, err, "negative.Count: expected gen tag min to fit in uint, but got -1")
+	err = mockhttp.NewPropertyTest[fraction]("POST", "/").Run(successHandler)
+	assert.EqualError(t, err, "fraction.Count: expected gen tag min to fit in int, but got 0.5")
+	err = mockhttp.NewPropertyTest[choice]("POST", "/").Run(successHandler)
+	assert.EqualError(t, err, `choice.Count: expected gen tag oneof values to fit in uint8, but got "300"`)
+}
+
+func TestGenerate_WideBounds(t *testing.T) {
+	type wide struct {
+		Signed   int64   `gen:"min=-9000000000000000000,max=9000000000000000000"`
+		Unsigned uint64  `gen:"min=0,max=18000000000000000000"`
+		Small    int8    `gen:"min=-128,max=127"`
+		Float    float64 `gen:"min=-1e308,max=1e308"`
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		w := mockhttp.Generate[wide](rng)
+		assert.GreaterOrEqual(t, w.Signed, int64(-9000000000000000000))
+		assert.LessOrEqual(t, w.Signed, int64(9000000000000000000))
+		assert.LessOrEqual(t, w.Unsigned, uint64(18000000000000000000))
+		assert.False(t, math.IsInf(w.Float, 0) || math.IsNaN(w.Float))
+	}
+
+	err := mockhttp.NewPropertyTest[wide]("POST", "/").WithSeed(1).Run(func(w http.ResponseWriter, r *http.Request) {
+		body, err := response.DecodeJSON[wide](w, r, nil)
+		if err != nil {
+			return
+		}
+		if body.Unsigned > 10 {
+			response.Error(w, http.StatusInternalServerError, "too many", nil)
+			return
+		}
+		response.Success(w)
+	})
+	var failure *mockhttp.PropertyFailure
+	assert.True(t, errors.As(err, &failure))
+	assert.Equal(t, `{"Signed":0,"Unsigned":11,"Small":0,"Float":0}`, failure.Body)
+}
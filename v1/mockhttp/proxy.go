@@ -0,0 +1,47 @@
+package mockhttp
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// WithProxy forwards requests that match no stub to upstream instead of
+// answering them with a 404, so only some endpoints of a dependency need
+// to be stubbed. Pass nil to turn proxying off
+func (s *StubHandler) WithProxy(upstream *url.URL) *StubHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upstream = upstream
+	s.proxy = nil
+	if upstream == nil {
+		return s
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = upstream.Host
+	}
+	s.proxy = proxy
+	return s
+}
+
+// WithLogger logs whether each request was stubbed, proxied or unmatched
+func (s *StubHandler) WithLogger(logger *log.Logger) *StubHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logger = logger
+	return s
+}
+
+func (s *StubHandler) logf(format string, args ...interface{}) {
+	s.mu.Lock()
+	logger := s.logger
+	s.mu.Unlock()
+	if logger != nil {
+		logger.Printf(format, args...)
+	}
+}
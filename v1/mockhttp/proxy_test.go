@@ -0,0 +1,63 @@
+package mockhttp_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStubHandler_Proxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+
+	var logs bytes.Buffer
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.WithProxy(upstreamURL).WithLogger(log.New(&logs, "", 0))
+	server.Register(mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/flaky")).WillReturn(200, "stubbed"))
+
+	assert.Equal(t, "stubbed", get(t, server.URL()+"/flaky"))
+	post(t, server.URL()+"/stable", "payload")
+
+	requests := server.Requests()
+	assert.True(t, requests[0].Matched)
+	assert.False(t, requests[0].Proxied)
+	assert.False(t, requests[1].Matched)
+	assert.True(t, requests[1].Proxied)
+	assert.Equal(t, "stubbed GET /flaky\nproxied POST /stable to "+upstream.URL+"\n", logs.String())
+
+	server.WithProxy(nil)
+	res, err := http.Get(server.URL() + "/stable")
+	assert.Nil(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
+
+func TestStubHandler_ProxyForwardsBody(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(bytes.Buffer)
+		body.ReadFrom(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " " + body.String()))
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.WithProxy(upstreamURL)
+
+	res, err := http.Post(server.URL()+"/things?id=1", "text/plain", bytes.NewBufferString("payload"))
+	assert.Nil(t, err)
+	raw, err := mockhttp.ToResponse(res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "POST /things?id=1 payload", raw.Body())
+}
@@ -29,6 +29,8 @@ func (s PathParamType) String() string {
 type Request struct {
 	W *httptest.ResponseRecorder
 	R *http.Request
+
+	recorded bool
 }
 
 // NewRequest creates a wrapper object around objects necessary to do a mock http request
@@ -48,7 +50,33 @@ func (r *Request) WithContext(ctx context.Context) *Request {
 	return r
 }
 
+// ContextFunc derives a new context from ctx, for example by adding a value.
+// Auth and tracing libraries often export helpers of this shape
+type ContextFunc func(ctx context.Context) context.Context
+
+// WithValue returns a ContextFunc that stores val under key. Use an unexported
+// key type, such as type ctxKey struct{}, so the handler under test sees the
+// same context it would in production
+func WithValue[K comparable, V any](key K, val V) ContextFunc {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, key, val)
+	}
+}
+
+// WithContextFuncs applies each ContextFunc to the Request's context in order
+func (r *Request) WithContextFuncs(fns ...ContextFunc) *Request {
+	ctx := r.R.Context()
+	for _, fn := range fns {
+		ctx = fn(ctx)
+	}
+	r.R = r.R.WithContext(ctx)
+	return r
+}
+
 // Inserts each key/value pair into context that gets inserted into the Request
+//
+// Deprecated: string keys collide across packages and can't reproduce the
+// typed keys real middleware uses. Use WithContextFuncs with WithValue instead
 func (r *Request) WithValues(vals map[string]interface{}) *Request {
 	ctx := r.R.Context()
 	for key, val := range vals {
@@ -81,6 +109,16 @@ func (r *Request) withChiPathParams(vals map[string]string) *Request {
 	return r
 }
 
+// Serve sends the request through the real router, so patterns, methods and
+// middleware are exercised as they are in production, and returns the
+// response. Path params faked with WithPathParams are dropped and the router
+// sets its own, so the same test cases can run with or without routing
+func (r *Request) Serve(router http.Handler) *http.Response {
+	r.R = r.R.WithContext(context.WithValue(r.R.Context(), chi.RouteCtxKey, nil))
+	router.ServeHTTP(r.W, r.R)
+	return r.Result()
+}
+
 // SetHeader sets HTTP Header for wrapper object
 func (r *Request) SetHeader(key, value string) *Request {
 	r.R.Header.Set(key, value)
@@ -95,7 +133,13 @@ func (r *Request) WithHeaders(vals map[string]string) *Request {
 	return r
 }
 
-// Result returns the *http.Response associated with the Http Request
+// Result returns the *http.Response associated with the Http Request.
+// The first call counts towards any Coverage being tracked
 func (r *Request) Result() *http.Response {
-	return r.W.Result()
+	res := r.W.Result()
+	if !r.recorded {
+		r.recorded = true
+		recordCoverage(r.R.Method, r.R.URL.Path, res.StatusCode)
+	}
+	return res
 }
@@ -0,0 +1,55 @@
+package mockhttp_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+type userKey struct{}
+
+type tenantKey string
+
+func withUser(ctx context.Context, u user) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+func TestRequest_WithContextFuncs(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/users/me", "").
+		WithContextFuncs(
+			mockhttp.WithValue(tenantKey("tenant"), "acme"),
+			func(ctx context.Context) context.Context { return withUser(ctx, user{ID: 1, Name: "wax"}) },
+			mockhttp.WithValue(tenantKey("tenant"), "globex"),
+		)
+
+	assert.Equal(t, user{ID: 1, Name: "wax"}, httpReq.Context().Value(userKey{}))
+	assert.Equal(t, "globex", httpReq.Context().Value(tenantKey("tenant")))
+	assert.Nil(t, httpReq.Context().Value("tenant"))
+}
+
+func TestRequest_Serve(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(requestID)
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(chi.URLParam(r, "id") + " " + r.Context().Value(requestIDKey{}).(string)))
+	})
+
+	res, err := mockhttp.ToResponse(mockhttp.NewRequest("GET", "/users/1", "").
+		WithPathParams(mockhttp.Chi, map[string]string{"id": "fake"}).
+		Serve(router))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, res.Status())
+	assert.Equal(t, "1 generated", res.Body())
+
+	res, err = mockhttp.ToResponse(mockhttp.NewRequest("DELETE", "/users/1", "").Serve(router))
+	assert.Nil(t, err)
+	assert.Equal(t, 405, res.Status())
+
+	res, err = mockhttp.ToResponse(mockhttp.NewRequest("GET", "/users", "").Serve(router))
+	assert.Nil(t, err)
+	assert.Equal(t, 404, res.Status())
+}
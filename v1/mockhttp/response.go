@@ -2,10 +2,15 @@ package mockhttp
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 type Response interface {
@@ -43,8 +48,11 @@ func (r *RawResponse) WithBody(body string) *RawResponse {
 type JSONResponse[T any] struct {
 	status         int
 	body           string
+	header         http.Header
 	Val            *T
 	validationFunc func(expected, result T) error
+	// err records a misused builder, and is returned by Validate
+	err error
 }
 
 func NewJSONResponse[T any]() *JSONResponse[T] {
@@ -64,12 +72,47 @@ func (r *JSONResponse[T]) WithStatus(status int) *JSONResponse[T] {
 	return r
 }
 
-func (r *JSONResponse[T]) WithSuccess(val *T) *JSONResponse[T] {
-	r.status = 200
+// Header returns the headers of a parsed response, or the expected headers
+func (r *JSONResponse[T]) Header() http.Header {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	return r.header
+}
+
+// WithSuccess expects a 200, or the 2xx status given, e.g. http.StatusAccepted.
+// Validate returns an error for any other status; use WithFailure for those
+func (r *JSONResponse[T]) WithSuccess(val *T, status ...int) *JSONResponse[T] {
+	r.status = http.StatusOK
+	switch {
+	case len(status) > 1:
+		r.err = fmt.Errorf("expected at most one success status, but got %v", status)
+	case len(status) == 1:
+		r.status = status[0]
+		if status[0] < 200 || status[0] > 299 {
+			r.err = fmt.Errorf("expected a 2xx success status, but got %d", status[0])
+		}
+	}
 	r.Val = val
 	return r
 }
 
+// WithCreated expects a 201 with a Location header, unless location is empty
+func (r *JSONResponse[T]) WithCreated(location string, val *T) *JSONResponse[T] {
+	r.status = http.StatusCreated
+	r.Val = val
+	if location != "" {
+		r.WithHeader("Location", location)
+	}
+	return r
+}
+
+// WithHeader expects the response to have the header set to value
+func (r *JSONResponse[T]) WithHeader(key, value string) *JSONResponse[T] {
+	r.Header().Set(key, value)
+	return r
+}
+
 func (r *JSONResponse[T]) WithFailure(status int, val *T) *JSONResponse[T] {
 	r.status = status
 	r.Val = val
@@ -100,6 +143,26 @@ func ToResponse(res *http.Response) (*RawResponse, error) {
 // It saves the status and parses the body into the expected type
 // It will return an error if the http.Response is not a 200
 func ToJSONResponse[T any](res *http.Response) (*JSONResponse[T], error) {
+	return toDecodedResponse[T](res, json.Unmarshal)
+}
+
+// ToXMLResponse is like ToJSONResponse for XML bodies. The result is a JSONResponse
+// so the same expectations and validation funcs can be used for every encoding
+func ToXMLResponse[T any](res *http.Response) (*JSONResponse[T], error) {
+	return toDecodedResponse[T](res, xml.Unmarshal)
+}
+
+// ToMsgPackResponse is like ToJSONResponse for MessagePack bodies
+func ToMsgPackResponse[T any](res *http.Response) (*JSONResponse[T], error) {
+	return toDecodedResponse[T](res, msgpack.Unmarshal)
+}
+
+// ToCBORResponse is like ToJSONResponse for CBOR bodies
+func ToCBORResponse[T any](res *http.Response) (*JSONResponse[T], error) {
+	return toDecodedResponse[T](res, cbor.Unmarshal)
+}
+
+func toDecodedResponse[T any](res *http.Response, unmarshal func([]byte, interface{}) error) (*JSONResponse[T], error) {
 	defer res.Body.Close()
 	data, err := ioutil.ReadAll(res.Body)
 	if err != nil {
@@ -109,6 +172,7 @@ func ToJSONResponse[T any](res *http.Response) (*JSONResponse[T], error) {
 	ret := &JSONResponse[T]{
 		status: res.StatusCode,
 		body:   string(data),
+		header: res.Header,
 	}
 
 	if len(ret.body) == 0 {
@@ -117,7 +181,7 @@ func ToJSONResponse[T any](res *http.Response) (*JSONResponse[T], error) {
 
 	var t T
 	ret.Val = &t
-	err = json.Unmarshal(data, ret.Val)
+	err = unmarshal(data, ret.Val)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +197,22 @@ func (expected *JSONResponse[T]) Validate(result *JSONResponse[T]) error {
 	if result == nil {
 		return errors.New("parameter result should not be nil")
 	}
+	if expected.err != nil {
+		return expected.err
+	}
 	if expected.status != result.status {
 		return fmt.Errorf("expected status %d, but got %d", expected.status, result.status)
 	}
+	keys := make([]string, 0, len(expected.header))
+	for key := range expected.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if want, got := expected.header.Get(key), result.Header().Get(key); want != got {
+			return fmt.Errorf("expected header %s: %s, but got %s", key, want, got)
+		}
+	}
 	if expected.validationFunc != nil {
 		return expected.validationFunc(*expected.Val, *result.Val)
 	}
@@ -190,6 +190,59 @@ func TestValidate_NoValidationFunc(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestJSONResponse_WithCreated(t *testing.T) {
+	expected := mockhttp.NewJSONResponse[response.IdStruct]().
+		WithCreated("/things/1", &response.IdStruct{Id: 1})
+
+	httpReq := mockhttp.NewRequest("POST", "/things", "")
+	createdHandler(httpReq.W, httpReq.R)
+	result, err := mockhttp.ToJSONResponse[response.IdStruct](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 201, result.Status())
+	assert.Equal(t, "/things/1", result.Header().Get("Location"))
+	assert.Nil(t, expected.Validate(result))
+
+	wrongLocation := mockhttp.NewJSONResponse[response.IdStruct]().
+		WithCreated("/things/2", &response.IdStruct{Id: 2})
+	err = wrongLocation.Validate(result)
+	assert.NotNil(t, err)
+	assert.Equal(t, "expected header Location: /things/2, but got /things/1", err.Error())
+}
+
+func TestJSONResponse_WithSuccessStatus(t *testing.T) {
+	expected := mockhttp.NewJSONResponse[response.StatusStruct]().
+		WithSuccess(&response.StatusStruct{Status: "queued"}, http.StatusAccepted)
+
+	httpReq := mockhttp.NewRequest("POST", "/jobs", "")
+	acceptedHandler(httpReq.W, httpReq.R)
+	result, err := mockhttp.ToJSONResponse[response.StatusStruct](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 202, expected.Status())
+	assert.Nil(t, expected.Validate(result))
+	assert.NotNil(t, mockhttp.NewJSONResponse[response.StatusStruct]().
+		WithSuccess(&response.StatusStruct{Status: "queued"}).
+		Validate(result))
+}
+
+func TestJSONResponse_WithSuccessRejectsFailureStatus(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	failHandler(httpReq.W, httpReq.R)
+	result, err := mockhttp.ToJSONResponse[response.StatusStruct](httpReq.Result())
+	assert.Nil(t, err)
+
+	err = mockhttp.NewJSONResponse[response.StatusStruct]().
+		WithSuccess(&response.StatusStruct{Status: "ok"}, result.Status()).
+		Validate(result)
+	assert.EqualError(t, err, fmt.Sprintf("expected a 2xx success status, but got %d", result.Status()))
+
+	err = mockhttp.NewJSONResponse[response.StatusStruct]().
+		WithSuccess(&response.StatusStruct{Status: "ok"}, http.StatusOK, http.StatusAccepted).
+		Validate(result)
+	assert.EqualError(t, err, "expected at most one success status, but got [200 202]")
+}
+
 func successHandler(w http.ResponseWriter, r *http.Request) {
 	response.Success(w)
 }
@@ -198,4 +251,12 @@ func failHandler(w http.ResponseWriter, r *http.Request) {
 	response.Error(w, 400, "something bad", nil)
 }
 
+func createdHandler(w http.ResponseWriter, r *http.Request) {
+	response.Created(w, "/things/1", response.IdStruct{Id: 1})
+}
+
+func acceptedHandler(w http.ResponseWriter, r *http.Request) {
+	response.Accepted(w, response.StatusStruct{Status: "queued"})
+}
+
 func nothingHandler(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,150 @@
+package mockhttp_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubSigner(t *testing.T) {
+	// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries#testing-the-webhook-payload-validation
+	httpReq := mockhttp.NewRequest("POST", "/webhooks/github", "Hello, World!").
+		SignWith(mockhttp.GitHubSigner{Secret: []byte("It's a Secret to Everybody")})
+
+	assert.Equal(t, "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", httpReq.R.Header.Get("X-Hub-Signature-256"))
+	body, _ := io.ReadAll(httpReq.R.Body)
+	assert.Equal(t, "Hello, World!", string(body))
+}
+
+func TestStripeSigner(t *testing.T) {
+	secret := []byte("whsec_test")
+	tests := []mockhttp.TestStruct{
+		{
+			Name: "valid",
+			Input: mockhttp.NewRequest("POST", "/webhooks/stripe", `{"type":"charge.succeeded"}`).
+				SignWith(mockhttp.StripeSigner{Secret: secret}),
+			Expected: mockhttp.NewRawResponse().WithStatus(200),
+		},
+		{
+			Name: "expired",
+			Input: mockhttp.NewRequest("POST", "/webhooks/stripe", `{"type":"charge.succeeded"}`).
+				SignWith(mockhttp.StripeSigner{Secret: secret, Timestamp: time.Now().Add(-time.Hour)}),
+			Expected: mockhttp.NewRawResponse().WithStatus(400),
+		},
+		{
+			Name: "wrong_secret",
+			Input: mockhttp.NewRequest("POST", "/webhooks/stripe", `{"type":"charge.succeeded"}`).
+				SignWith(mockhttp.StripeSigner{Secret: []byte("whsec_other")}),
+			Expected: mockhttp.NewRawResponse().WithStatus(400),
+		},
+		{
+			Name: "tampered",
+			Input: mockhttp.NewRequest("POST", "/webhooks/stripe", `{"type":"charge.succeeded"}`).
+				SignWith(mockhttp.StripeSigner{Secret: secret}).
+				SignWith(mockhttp.SignerFunc(func(r *http.Request, body []byte) {
+					r.Body = io.NopCloser(strings.NewReader(`{"type":"charge.refunded"}`))
+				})),
+			Expected: mockhttp.NewRawResponse().WithStatus(400),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			stripeWebhook(secret)(tt.Input.W, tt.Input.R)
+			assert.Equal(t, tt.Expected.Status(), tt.Input.W.Code)
+		})
+	}
+}
+
+func TestSigV4Signer(t *testing.T) {
+	// Expected values were produced by aws-sdk-go-v2's v4.Signer with the same inputs
+	httpReq := mockhttp.NewRequest("GET", "https://example.amazon.com/", "").
+		SignWith(mockhttp.SigV4Signer{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			Region:          "us-east-1",
+			Service:         "service",
+			Time:            time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+		})
+
+	assert.Equal(t, "20150830T123600Z", httpReq.R.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=7ab4567ae243ee168f6bf18206b2b40b61ce08277323168138fa113ed23c538e", httpReq.R.Header.Get("Authorization"))
+}
+
+func TestSigV4Signer_SortsQueryByName(t *testing.T) {
+	// "id2=b" sorts before "id=a" as a whole pair, but after it by name
+	httpReq := mockhttp.NewRequest("GET", "https://example.amazon.com/items?id2=b&id=a&id.x=c&id-y=d", "").
+		SignWith(mockhttp.SigV4Signer{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			Region:          "us-east-1",
+			Service:         "service",
+			Time:            time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+		})
+
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=7499394f403610ee02142013a9fcd592b077fcd37553f8a48e223c3548097db9", httpReq.R.Header.Get("Authorization"))
+}
+
+func TestSigV4Signer_SignsAmzHeaders(t *testing.T) {
+	httpReq := mockhttp.NewRequest("PUT", "https://bucket.s3.amazonaws.com/key?b=2&a=1", "data").
+		SetHeader("Content-Type", "text/plain").
+		SignWith(mockhttp.SigV4Signer{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+			SessionToken:    "token",
+			Region:          "us-east-1",
+			Service:         "s3",
+			Time:            time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+			ContentSHA256:   true,
+		})
+
+	sum := sha256.Sum256([]byte("data"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), httpReq.R.Header.Get("X-Amz-Content-Sha256"))
+	assert.Equal(t, "token", httpReq.R.Header.Get("X-Amz-Security-Token"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/s3/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, "+
+		"Signature=51edba08ce6636f2bb2f853d23e71eb47ba4ed7788b127c0faf21d678575aabb", httpReq.R.Header.Get("Authorization"))
+}
+
+// stripeWebhook verifies signatures the way a Stripe webhook receiver would
+func stripeWebhook(secret []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ts, sig string
+		for _, part := range strings.Split(r.Header.Get("Stripe-Signature"), ",") {
+			key, val, _ := strings.Cut(part, "=")
+			switch key {
+			case "t":
+				ts = val
+			case "v1":
+				sig = val
+			}
+		}
+		unix, _ := strconv.ParseInt(ts, 10, 64)
+		if time.Since(time.Unix(unix, 0)) > 5*time.Minute {
+			response.Error(w, http.StatusBadRequest, "signature expired", nil)
+			return
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(ts + "." + string(body)))
+		if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(sig)) {
+			response.Error(w, http.StatusBadRequest, "invalid signature", nil)
+			return
+		}
+		response.Success(w)
+	}
+}
@@ -0,0 +1,161 @@
+package mockhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a parsed Server-Sent Event. Data holds the event's data decoded as
+// JSON into T, or as is when T is a string. RawData always holds it as sent
+type Event[T any] struct {
+	ID      string
+	Event   string
+	Data    T
+	RawData string
+	Retry   time.Duration
+}
+
+// SSEReader reads events from a Server-Sent Events response one at a time
+type SSEReader[T any] struct {
+	scanner *bufio.Scanner
+	body    io.ReadCloser
+	close   func()
+}
+
+// maxSSELineSize is the longest line, such as a single data: line, an SSEReader reads
+const maxSSELineSize = 16 << 20
+
+// NewSSEReader reads events from the body of res. Lines longer than 16 MiB
+// fail with bufio.ErrTooLong
+func NewSSEReader[T any](res *http.Response) *SSEReader[T] {
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(nil, maxSSELineSize)
+	return &SSEReader[T]{
+		scanner: scanner,
+		body:    res.Body,
+	}
+}
+
+// Next blocks until the next event arrives and returns io.EOF when the stream ends
+func (r *SSEReader[T]) Next() (Event[T], error) {
+	var ev Event[T]
+	var data []string
+	hasData, hasFields := false, false
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" {
+			if !hasData && !hasFields {
+				continue
+			}
+			ev.RawData = strings.Join(data, "\n")
+			if !hasData {
+				return ev, nil
+			}
+			return ev, decodeEventData(&ev)
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, val, _ := strings.Cut(line, ":")
+		val = strings.TrimPrefix(val, " ")
+		switch field {
+		case "id":
+			ev.ID = val
+			hasFields = true
+		case "event":
+			ev.Event = val
+			hasFields = true
+		case "retry":
+			ms, err := strconv.Atoi(val)
+			if err != nil {
+				return ev, fmt.Errorf("invalid retry %q: %w", val, err)
+			}
+			ev.Retry = time.Duration(ms) * time.Millisecond
+			hasFields = true
+		case "data":
+			data = append(data, val)
+			hasData = true
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return ev, err
+	}
+	return ev, io.EOF
+}
+
+// All reads every remaining event until the stream ends
+func (r *SSEReader[T]) All() ([]Event[T], error) {
+	var events []Event[T]
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
+
+// Close closes the response body, and stops the server if the reader came from ServeSSE
+func (r *SSEReader[T]) Close() error {
+	err := r.body.Close()
+	if r.close != nil {
+		r.close()
+	}
+	return err
+}
+
+func decodeEventData[T any](ev *Event[T]) error {
+	if s, ok := any(&ev.Data).(*string); ok {
+		*s = ev.RawData
+		return nil
+	}
+	if err := json.Unmarshal([]byte(ev.RawData), &ev.Data); err != nil {
+		return fmt.Errorf("unable to decode data of event %q: %w", ev.ID, err)
+	}
+	return nil
+}
+
+// ToSSEResponse parses a recorded Server-Sent Events response into its events
+// It returns an error if the response isn't a text/event-stream
+func ToSSEResponse[T any](res *http.Response) ([]Event[T], error) {
+	reader := NewSSEReader[T](res)
+	defer reader.Close()
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		return nil, fmt.Errorf("expected Content-Type text/event-stream, but got %s", contentType)
+	}
+	return reader.All()
+}
+
+// ServeSSE serves handler from a real httptest.Server and sends it req, so events
+// can be read as they are flushed instead of after the handler returns.
+// Only the method, URL, headers and body of req are sent; its context only
+// controls cancellation. Close the reader to disconnect and stop the server
+func ServeSSE[T any](handler http.HandlerFunc, req *Request) (*SSEReader[T], error) {
+	server := httptest.NewServer(handler)
+	r, err := http.NewRequestWithContext(req.Context(), req.R.Method, server.URL+req.R.URL.RequestURI(), req.R.Body)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	r.Header = req.R.Header.Clone()
+
+	res, err := server.Client().Do(r)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	reader := NewSSEReader[T](res)
+	reader.close = server.Close
+	return reader, nil
+}
@@ -0,0 +1,147 @@
+package mockhttp_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToSSEResponse(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/events", "")
+	sseHandler(httpReq.W, httpReq.R)
+
+	events, err := mockhttp.ToSSEResponse[thing](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, mockhttp.Event[thing]{
+		ID:      "1",
+		Event:   "created",
+		Data:    thing{ID: 1, Name: "wax"},
+		RawData: `{"id":1,"name":"wax"}`,
+		Retry:   3 * time.Second,
+	}, events[0])
+	assert.Equal(t, "2", events[1].ID)
+	assert.Equal(t, "bee", events[1].Data.Name)
+}
+
+func TestToSSEResponse_StringData(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/events", "")
+	stream, _ := response.NewSSE(httpReq.W, httpReq.R)
+	stream.Comment("keep-alive")
+	stream.Send(response.Event{Data: "line one\nline two"})
+	stream.Send(response.Event{ID: "no-data"})
+
+	events, err := mockhttp.ToSSEResponse[string](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "line one\nline two", events[0].Data)
+	assert.Equal(t, "no-data", events[1].ID)
+}
+
+func TestToSSEResponse_LineBreaks(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/events", "")
+	stream, _ := response.NewSSE(httpReq.W, httpReq.R)
+	stream.Comment("one\r\ndata: injected")
+	stream.Send(response.Event{Data: "line one\r\nline two\rline three"})
+
+	assert.EqualError(t, stream.Send(response.Event{ID: "1\ndata: injected"}),
+		`expected an event ID without line breaks, but got "1\ndata: injected"`)
+	assert.EqualError(t, stream.Send(response.Event{Event: "created\r"}),
+		`expected an event type without line breaks, but got "created\r"`)
+
+	events, err := mockhttp.ToSSEResponse[string](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "line one\nline two\nline three", events[0].Data)
+}
+
+func TestToSSEResponse_LongLine(t *testing.T) {
+	data := strings.Repeat("a", 100_000)
+	httpReq := mockhttp.NewRequest("GET", "/events", "")
+	stream, _ := response.NewSSE(httpReq.W, httpReq.R)
+	stream.Send(response.Event{Data: data})
+
+	events, err := mockhttp.ToSSEResponse[string](httpReq.Result())
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, data, events[0].Data)
+}
+
+func TestToSSEResponse_NotAStream(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/", "")
+	successHandler(httpReq.W, httpReq.R)
+
+	_, err := mockhttp.ToSSEResponse[string](httpReq.Result())
+
+	assert.Equal(t, "expected Content-Type text/event-stream, but got application/json", err.Error())
+}
+
+func TestServeSSE_Incremental(t *testing.T) {
+	release := make(chan struct{})
+	disconnected := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		stream, err := response.NewSSE(w, r)
+		if err != nil {
+			return
+		}
+		stream.Send(response.Event{ID: "1", Data: thing{ID: 1}})
+		<-release
+		stream.Send(response.Event{ID: "2", Data: thing{ID: 2}})
+		<-stream.Done()
+		close(disconnected)
+	}
+
+	reader, err := mockhttp.ServeSSE[thing](handler, mockhttp.NewRequest("GET", "/events", ""))
+	assert.Nil(t, err)
+
+	// the first event arrives while the handler is still blocked
+	ev, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ev.Data.ID)
+
+	close(release)
+	ev, err = reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, ev.Data.ID)
+
+	reader.Close()
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the handler to see the client disconnect")
+	}
+}
+
+func TestSSE_SendAfterDisconnect(t *testing.T) {
+	httpReq := mockhttp.NewRequest("GET", "/events", "")
+	ctx, cancel := context.WithCancel(httpReq.Context())
+	httpReq.WithContext(ctx)
+
+	stream, err := response.NewSSE(httpReq.W, httpReq.R)
+	assert.Nil(t, err)
+	cancel()
+
+	assert.Equal(t, context.Canceled, stream.Send(response.Event{Data: "late"}))
+	events, err := mockhttp.NewSSEReader[string](httpReq.Result()).All()
+	assert.Nil(t, err)
+	assert.Len(t, events, 0)
+}
+
+func sseHandler(w http.ResponseWriter, r *http.Request) {
+	stream, err := response.NewSSE(w, r)
+	if err != nil {
+		return
+	}
+	stream.Send(response.Event{ID: "1", Event: "created", Data: thing{ID: 1, Name: "wax"}, Retry: 3 * time.Second})
+	stream.Send(response.Event{ID: "2", Event: "created", Data: thing{ID: 2, Name: "bee"}})
+}
@@ -0,0 +1,617 @@
+package mockhttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sachsry/mockhttp/v1/response"
+)
+
+// RequestMatcher inspects a recorded request and returns an error describing
+// why it does not match, or nil if it does
+type RequestMatcher func(r *RecordedRequest) error
+
+// RequestPattern describes the requests a stub answers or a verification counts.
+// Empty fields match anything. Path segments written as {name} match any single
+// segment, and a trailing * matches the rest of the path
+type RequestPattern struct {
+	Method   string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Path     string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Query    map[string]string `json:"query,omitempty" yaml:"query,omitempty"`
+	Body     string            `json:"body,omitempty" yaml:"body,omitempty"`
+	JSONBody interface{}       `json:"jsonBody,omitempty" yaml:"jsonBody,omitempty"`
+	matchers []RequestMatcher
+}
+
+// NewRequestPattern creates a pattern matching the given method and path
+func NewRequestPattern(method, path string) *RequestPattern {
+	return &RequestPattern{
+		Method: method,
+		Path:   path,
+	}
+}
+
+func (p *RequestPattern) WithHeader(key, value string) *RequestPattern {
+	if p.Headers == nil {
+		p.Headers = map[string]string{}
+	}
+	p.Headers[key] = value
+	return p
+}
+
+func (p *RequestPattern) WithQuery(key, value string) *RequestPattern {
+	if p.Query == nil {
+		p.Query = map[string]string{}
+	}
+	p.Query[key] = value
+	return p
+}
+
+// WithBody requires the request body to equal body exactly
+func (p *RequestPattern) WithBody(body string) *RequestPattern {
+	p.Body = body
+	return p
+}
+
+// WithJSONBody requires the request body to be JSON equivalent to val,
+// ignoring key order and whitespace
+func (p *RequestPattern) WithJSONBody(val interface{}) *RequestPattern {
+	p.JSONBody = val
+	return p
+}
+
+// WithMatcher adds a custom matcher that must also pass
+func (p *RequestPattern) WithMatcher(m RequestMatcher) *RequestPattern {
+	p.matchers = append(p.matchers, m)
+	return p
+}
+
+func (p *RequestPattern) String() string {
+	method := p.Method
+	if method == "" {
+		method = "ANY"
+	}
+	path := p.Path
+	if path == "" {
+		path = "*"
+	}
+	return method + " " + path
+}
+
+// Matches reports whether the recorded request satisfies every part of the pattern
+func (p *RequestPattern) Matches(r *RecordedRequest) bool {
+	return len(p.mismatches(r)) == 0
+}
+
+// mismatches lists every reason the request does not match the pattern
+func (p *RequestPattern) mismatches(r *RecordedRequest) []string {
+	var ret []string
+	if p.Method != "" && !strings.EqualFold(p.Method, r.Method) {
+		ret = append(ret, fmt.Sprintf("expected method %s, but got %s", p.Method, r.Method))
+	}
+	if p.Path != "" {
+		if _, ok := matchPath(p.Path, r.Path); !ok {
+			ret = append(ret, fmt.Sprintf("expected path %s, but got %s", p.Path, r.Path))
+		}
+	}
+	for _, key := range sortedKeys(p.Headers) {
+		if got := r.Header.Get(key); got != p.Headers[key] {
+			ret = append(ret, fmt.Sprintf("expected header %s: %q, but got %q", key, p.Headers[key], got))
+		}
+	}
+	for _, key := range sortedKeys(p.Query) {
+		if got := r.Query.Get(key); got != p.Query[key] {
+			ret = append(ret, fmt.Sprintf("expected query %s: %q, but got %q", key, p.Query[key], got))
+		}
+	}
+	if p.Body != "" && p.Body != r.Body {
+		ret = append(ret, fmt.Sprintf("expected body %q, but got %q", p.Body, r.Body))
+	}
+	if p.JSONBody != nil {
+		ret = append(ret, diffJSONBody(p.JSONBody, r.Body)...)
+	}
+	for _, m := range p.matchers {
+		if err := m(r); err != nil {
+			ret = append(ret, err.Error())
+		}
+	}
+	return ret
+}
+
+// MatchJSON decodes the request body into T and compares it to expected using
+// the same validation funcs accepted by JSONResponse.WithValidationFunc.
+// If f is nil the values must be deeply equal
+func MatchJSON[T any](expected T, f func(expected, result T) error) RequestMatcher {
+	return func(r *RecordedRequest) error {
+		var result T
+		if err := json.Unmarshal([]byte(r.Body), &result); err != nil {
+			return fmt.Errorf("expected a JSON body, but got %q: %v", r.Body, err)
+		}
+		if f != nil {
+			return f(expected, result)
+		}
+		if !reflect.DeepEqual(expected, result) {
+			return fmt.Errorf("expected body %+v, but got %+v", expected, result)
+		}
+		return nil
+	}
+}
+
+// RecordedRequest is a request received by a StubHandler
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Path    string      `json:"path"`
+	Query   url.Values  `json:"query,omitempty"`
+	Header  http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	Time    time.Time   `json:"time"`
+	Matched bool        `json:"matched"`
+	Proxied bool        `json:"proxied"`
+}
+
+func (r *RecordedRequest) String() string {
+	return r.Method + " " + r.URL
+}
+
+func newRecordedRequest(r *http.Request) (*RecordedRequest, error) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordedRequest{
+		Method: r.Method,
+		URL:    r.URL.RequestURI(),
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   string(data),
+		Time:   time.Now(),
+	}, nil
+}
+
+// StubResponse is the canned reply sent when a stub matches
+type StubResponse struct {
+	Status   int               `json:"status,omitempty" yaml:"status,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body     string            `json:"body,omitempty" yaml:"body,omitempty"`
+	JSONBody interface{}       `json:"jsonBody,omitempty" yaml:"jsonBody,omitempty"`
+	// BodyFile is read on every request, so edits show up without a reload
+	BodyFile string   `json:"bodyFile,omitempty" yaml:"bodyFile,omitempty"`
+	Delay    Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	// Template renders Body, BodyFile and header values with text/template,
+	// see TemplateData for the fields available
+	Template bool `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+// Stub pairs a request pattern with the response served when it matches
+type Stub struct {
+	// ID is assigned when the stub is registered if left empty
+	ID       string          `json:"id,omitempty" yaml:"id,omitempty"`
+	Request  *RequestPattern `json:"request" yaml:"request"`
+	Response *StubResponse   `json:"response" yaml:"response"`
+	// Scenario, RequiredState and NewState make a stub stateful: it only matches
+	// while the scenario is in RequiredState, and moves it to NewState when it does
+	Scenario      string `json:"scenario,omitempty" yaml:"scenario,omitempty"`
+	RequiredState string `json:"requiredState,omitempty" yaml:"requiredState,omitempty"`
+	NewState      string `json:"newState,omitempty" yaml:"newState,omitempty"`
+}
+
+// NewStub creates a stub for the pattern that replies with an empty 200 until told otherwise
+func NewStub(req *RequestPattern) *Stub {
+	return &Stub{
+		Request:  req,
+		Response: &StubResponse{Status: http.StatusOK},
+	}
+}
+
+func (s *Stub) WillReturn(status int, body string) *Stub {
+	s.Response.Status = status
+	s.Response.Body = body
+	return s
+}
+
+// WillReturnJSON replies with the JSON representation of val
+func (s *Stub) WillReturnJSON(status int, val interface{}) *Stub {
+	s.Response.Status = status
+	s.Response.JSONBody = val
+	return s
+}
+
+// InScenario only matches while the scenario is in requiredState, and moves
+// the scenario to newState when it does. Empty states are ignored
+func (s *Stub) InScenario(scenario, requiredState, newState string) *Stub {
+	s.Scenario = scenario
+	s.RequiredState = requiredState
+	s.NewState = newState
+	return s
+}
+
+// WillReturnFile replies with the contents of the file at path
+func (s *Stub) WillReturnFile(status int, path string) *Stub {
+	s.Response.Status = status
+	s.Response.BodyFile = path
+	return s
+}
+
+func (s *Stub) WithResponseHeader(key, value string) *Stub {
+	if s.Response.Headers == nil {
+		s.Response.Headers = map[string]string{}
+	}
+	s.Response.Headers[key] = value
+	return s
+}
+
+// WithDelay holds the response for d, or until the client gives up
+func (s *Stub) WithDelay(d time.Duration) *Stub {
+	s.Response.Delay = Duration(d)
+	return s
+}
+
+// WillReturnTemplate replies with body rendered as a text/template, see TemplateData
+func (s *Stub) WillReturnTemplate(status int, body string) *Stub {
+	s.Response.Status = status
+	s.Response.Body = body
+	s.Response.Template = true
+	return s
+}
+
+// write sends the stub response. When render is not nil it is applied to
+// the body and header values
+func (s *Stub) write(w http.ResponseWriter, r *http.Request, render func(string) (string, error)) {
+	res := s.Response
+	if res.Delay > 0 {
+		timer := time.NewTimer(time.Duration(res.Delay))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	body := []byte(res.Body)
+	switch {
+	case res.JSONBody != nil:
+		data, err := json.Marshal(res.JSONBody)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "unable to marshal stub response", err)
+			return
+		}
+		body = data
+		w.Header().Set("Content-Type", "application/json")
+	case res.BodyFile != "":
+		data, err := os.ReadFile(res.BodyFile)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "unable to read stub body file", err)
+			return
+		}
+		body = data
+	}
+	headers := res.Headers
+	if render != nil {
+		rendered, err := render(string(body))
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "unable to render stub template", err)
+			return
+		}
+		body = []byte(rendered)
+
+		headers = make(map[string]string, len(res.Headers))
+		for key, value := range res.Headers {
+			if headers[key], err = render(value); err != nil {
+				response.Error(w, http.StatusInternalServerError, "unable to render stub template", err)
+				return
+			}
+		}
+	}
+	for key, value := range headers {
+		w.Header().Set(key, value)
+	}
+	status := res.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// ScenarioStarted is the state every scenario begins in
+const ScenarioStarted = "Started"
+
+// StubHandler serves registered stubs and records every request it receives
+// so they can be verified after the code under test has run
+type StubHandler struct {
+	mu        sync.Mutex
+	defaults  []*Stub
+	stubs     []*Stub
+	calls     []*RecordedRequest
+	scenarios map[string]string
+	sequences map[string]int
+	upstream  *url.URL
+	proxy     *httputil.ReverseProxy
+	logger    *log.Logger
+}
+
+func NewStubHandler() *StubHandler {
+	return &StubHandler{
+		scenarios: map[string]string{},
+		sequences: map[string]int{},
+	}
+}
+
+// Register adds stubs to the handler. When several stubs match a request,
+// the most recently registered one wins
+func (s *StubHandler) Register(stubs ...*Stub) *StubHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assignIDs(stubs)
+	s.stubs = append(s.stubs, stubs...)
+	return s
+}
+
+// SetStubs replaces every registered stub
+func (s *StubHandler) SetStubs(stubs ...*Stub) *StubHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assignIDs(stubs)
+	s.stubs = append([]*Stub(nil), stubs...)
+	return s
+}
+
+// SetDefaultStubs replaces the default stubs, such as those loaded from a
+// config file. Defaults survive Reset and only match when no registered stub does
+func (s *StubHandler) SetDefaultStubs(stubs ...*Stub) *StubHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assignIDs(stubs)
+	s.defaults = append([]*Stub(nil), stubs...)
+	return s
+}
+
+// RemoveStub removes the registered stub with the given ID and reports whether it existed.
+// Default stubs can't be removed, only replaced with SetDefaultStubs
+func (s *StubHandler) RemoveStub(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, stub := range s.stubs {
+		if stub.ID == id {
+			s.stubs = append(s.stubs[:i:i], s.stubs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// isDefault reports whether id belongs to a default stub
+func (s *StubHandler) isDefault(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stub := range s.defaults {
+		if stub.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Stubs returns the default stubs followed by the registered stubs, in registration order
+func (s *StubHandler) Stubs() []*Stub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := append([]*Stub(nil), s.defaults...)
+	return append(ret, s.stubs...)
+}
+
+// Requests returns a copy of every request received, in the order received
+func (s *StubHandler) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make([]RecordedRequest, len(s.calls))
+	for i, call := range s.calls {
+		ret[i] = *call
+	}
+	return ret
+}
+
+// ResetRequests clears the recorded requests but keeps the stubs
+func (s *StubHandler) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// Scenarios returns the state of every scenario that has changed state
+func (s *StubHandler) Scenarios() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make(map[string]string, len(s.scenarios))
+	for name, state := range s.scenarios {
+		ret[name] = state
+	}
+	return ret
+}
+
+func (s *StubHandler) ScenarioState(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scenarioState(name)
+}
+
+func (s *StubHandler) SetScenarioState(name, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios[name] = state
+}
+
+// ResetScenarios moves every scenario back to ScenarioStarted
+func (s *StubHandler) ResetScenarios() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = map[string]string{}
+}
+
+// Reset clears the registered stubs, the recorded requests, the scenario
+// states and the template sequence counters. Default stubs are kept
+func (s *StubHandler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stubs = nil
+	s.calls = nil
+	s.scenarios = map[string]string{}
+	s.sequences = map[string]int{}
+}
+
+func (s *StubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec, err := newRecordedRequest(r)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "unable to read request body", err)
+		return
+	}
+
+	s.mu.Lock()
+	stub := s.match(rec)
+	rec.Matched = stub != nil
+	rec.Proxied = stub == nil && s.proxy != nil
+	s.calls = append(s.calls, rec)
+	if stub != nil && stub.Scenario != "" && stub.NewState != "" {
+		s.scenarios[stub.Scenario] = stub.NewState
+	}
+	proxy, upstream := s.proxy, s.upstream
+	s.mu.Unlock()
+
+	switch {
+	case stub != nil:
+		s.logf("stubbed %s", rec)
+		var render func(string) (string, error)
+		if stub.Response.Template {
+			render = s.renderer(stub, rec)
+		}
+		stub.write(w, r, render)
+	case proxy != nil:
+		s.logf("proxied %s to %s", rec, upstream)
+		r.Body = io.NopCloser(strings.NewReader(rec.Body))
+		proxy.ServeHTTP(w, r)
+	default:
+		s.logf("unmatched %s", rec)
+		response.Error(w, http.StatusNotFound, fmt.Sprintf("no stub matched %s", rec), nil)
+	}
+}
+
+// match must be called with the lock held
+func (s *StubHandler) match(rec *RecordedRequest) *Stub {
+	for _, stubs := range [][]*Stub{s.stubs, s.defaults} {
+		for i := len(stubs) - 1; i >= 0; i-- {
+			if s.inState(stubs[i]) && stubs[i].Request.Matches(rec) {
+				return stubs[i]
+			}
+		}
+	}
+	return nil
+}
+
+// inState must be called with the lock held
+func (s *StubHandler) inState(stub *Stub) bool {
+	if stub.Scenario == "" || stub.RequiredState == "" {
+		return true
+	}
+	return s.scenarioState(stub.Scenario) == stub.RequiredState
+}
+
+// scenarioState must be called with the lock held
+func (s *StubHandler) scenarioState(name string) string {
+	if state, ok := s.scenarios[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+func assignIDs(stubs []*Stub) {
+	for _, stub := range stubs {
+		if stub.ID == "" {
+			stub.ID = newStubID()
+		}
+	}
+}
+
+func newStubID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// StubServer is a StubHandler listening on a local port, for handing to
+// the code under test as the base URL of a dependency. The admin API is
+// served under AdminPrefix
+type StubServer struct {
+	*StubHandler
+	server *httptest.Server
+}
+
+func NewStubServer() *StubServer {
+	h := NewStubHandler()
+	return &StubServer{
+		StubHandler: h,
+		server:      httptest.NewServer(WithAdmin(h)),
+	}
+}
+
+// URL returns the base URL of the server, e.g. http://127.0.0.1:53021
+func (s *StubServer) URL() string {
+	return s.server.URL
+}
+
+func (s *StubServer) Close() {
+	s.server.Close()
+}
+
+// matchPath matches a path against a pattern and returns the values of
+// any {name} segments
+func matchPath(pattern, path string) (map[string]string, bool) {
+	params := map[string]string{}
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return params, true
+		}
+		if i >= len(pathParts) {
+			return nil, false
+		}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params[strings.Trim(part, "{}")] = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return nil, false
+		}
+	}
+	if len(patternParts) != len(pathParts) {
+		return nil, false
+	}
+	return params, true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package mockhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestStubServer_ServesStub(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users/{id}")).
+			WillReturnJSON(200, user{ID: 1, Name: "wax"}),
+	)
+
+	res, err := http.Get(server.URL() + "/users/1")
+	assert.Nil(t, err)
+	result, err := mockhttp.ToJSONResponse[user](res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 200, result.Status())
+	assert.Equal(t, "wax", result.Val.Name)
+}
+
+func TestStubServer_NoMatch(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+
+	res, err := http.Get(server.URL() + "/users/1")
+	assert.Nil(t, err)
+	result, err := mockhttp.ToJSONResponse[mockhttp.ServerError](res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 404, result.Status())
+	assert.Equal(t, "no stub matched GET /users/1", result.Val.DebugMessage)
+	assert.False(t, server.Requests()[0].Matched)
+}
+
+func TestStubServer_LatestStubWins(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users/*")).WillReturn(500, ""),
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users/{id}")).WillReturn(204, ""),
+	)
+
+	res, err := http.Get(server.URL() + "/users/1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 204, res.StatusCode)
+}
+
+func TestVerify_Times(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	post(t, server.URL()+"/users", `{"id":1,"name":"wax"}`)
+	post(t, server.URL()+"/users", `{"id":2,"name":"bee"}`)
+
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users")).Times(2))
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users").WithJSONBody(user{ID: 1, Name: "wax"})).Once())
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("DELETE", "/users/{id}")).Never())
+	assert.NotNil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users")).AtLeast(3))
+}
+
+func TestVerify_MatchJSON(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	post(t, server.URL()+"/users", `{"id":1,"name":"wax"}`)
+
+	byName := func(expected, result user) error {
+		if expected.Name != result.Name {
+			return errors.New("unexpected name: " + result.Name)
+		}
+		return nil
+	}
+
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users").
+		WithMatcher(mockhttp.MatchJSON(user{Name: "wax"}, byName))).Once())
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users").
+		WithMatcher(mockhttp.MatchJSON(user{ID: 1, Name: "wax"}, nil))).Once())
+	assert.Nil(t, server.Verify(mockhttp.NewRequestPattern("POST", "/users").
+		WithMatcher(mockhttp.MatchJSON(user{Name: "bee"}, byName))).Never())
+}
+
+func TestVerify_NearMisses(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	post(t, server.URL()+"/users", `{"id":1,"name":"wax","extra":true}`)
+
+	err := server.Verify(mockhttp.NewRequestPattern("POST", "/users").
+		WithHeader("X-Request-Id", "abc").
+		WithJSONBody(map[string]interface{}{"id": 1, "name": "bee"})).Once()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, `expected POST /users to be called 1 time(s), but it was called 0 time(s)
+closest requests:
+  POST /users
+    - expected header X-Request-Id: "abc", but got ""
+    - body.extra: unexpected value true
+    - body.name: expected "bee", but got "wax"`, err.Error())
+}
+
+func TestVerify_NoRequests(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+
+	err := server.Verify(mockhttp.NewRequestPattern("GET", "/users")).Once()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "expected GET /users to be called 1 time(s), but it was called 0 time(s)\nno requests were received", err.Error())
+}
+
+func TestVerifyInOrder(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	post(t, server.URL()+"/users", `{"id":1}`)
+	post(t, server.URL()+"/audit", `{}`)
+
+	create := mockhttp.NewRequestPattern("POST", "/users")
+	audit := mockhttp.NewRequestPattern("POST", "/audit")
+
+	assert.Nil(t, server.VerifyInOrder(create, audit))
+	err := server.VerifyInOrder(audit, create)
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "expected POST /users to be called after POST /audit, but it was not"))
+}
+
+func TestStubHandler_Reset(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/users")))
+	post(t, server.URL()+"/users", `{}`)
+
+	server.ResetRequests()
+	assert.Len(t, server.Requests(), 0)
+	assert.Len(t, server.Stubs(), 1)
+
+	server.Reset()
+	assert.Len(t, server.Stubs(), 0)
+}
+
+func post(t *testing.T, url, body string) {
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+}
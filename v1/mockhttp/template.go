@@ -0,0 +1,123 @@
+package mockhttp
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is available to templated stub responses. For example:
+//
+//	{"id": "{{.PathParams.id}}", "name": "{{.JSON.name}}", "trace": "{{.Headers.Get "X-Correlation-Id"}}"}
+//
+// Templates can also call these helpers:
+//
+//	field .JSON "a" "b"  the JSON field at that path, or "" if it's missing
+//	randomID             a random UUID
+//	now [layout]         the current time, formatted as RFC 3339 unless a layout is given
+//	timestamp            the current Unix time in seconds
+//	seq "name"           a counter that starts at 1 and increments on every use, until Reset
+type TemplateData struct {
+	Method     string
+	Path       string
+	PathParams map[string]string
+	Query      url.Values
+	Headers    http.Header
+	Body       string
+	// JSON is the request body decoded as a JSON object, or an empty object if
+	// the body is empty or isn't an object. A missing field renders as
+	// "<no value>", so use field for optional ones
+	JSON map[string]interface{}
+}
+
+func newTemplateData(stub *Stub, rec *RecordedRequest) *TemplateData {
+	data := &TemplateData{
+		Method:     rec.Method,
+		Path:       rec.Path,
+		PathParams: map[string]string{},
+		Query:      rec.Query,
+		Headers:    rec.Header,
+		Body:       rec.Body,
+		JSON:       map[string]interface{}{},
+	}
+	if stub.Request.Path != "" {
+		if params, ok := matchPath(stub.Request.Path, rec.Path); ok {
+			data.PathParams = params
+		}
+	}
+	if rec.Body != "" {
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(rec.Body), &body); err == nil && body != nil {
+			data.JSON = body
+		}
+	}
+	return data
+}
+
+// renderer returns a func that renders text as a template for the stub and request
+func (s *StubHandler) renderer(stub *Stub, rec *RecordedRequest) func(string) (string, error) {
+	data := newTemplateData(stub, rec)
+	funcs := template.FuncMap{
+		"field":    field,
+		"randomID": randomID,
+		"now": func(layout ...string) string {
+			if len(layout) > 0 {
+				return time.Now().Format(layout[0])
+			}
+			return time.Now().Format(time.RFC3339)
+		},
+		"timestamp": func() int64 {
+			return time.Now().Unix()
+		},
+		"seq": s.nextSequence,
+	}
+	return func(text string) (string, error) {
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+		tmpl, err := template.New(stub.ID).Funcs(funcs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// field walks keys through nested JSON objects, returning "" as soon as a key
+// is missing or a value isn't an object
+func field(val interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		if val, ok = obj[key]; !ok || val == nil {
+			return ""
+		}
+	}
+	return val
+}
+
+func (s *StubHandler) nextSequence(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sequences[name]++
+	return s.sequences[name]
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
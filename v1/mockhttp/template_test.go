@@ -0,0 +1,145 @@
+package mockhttp_test
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/mockhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStubTemplate_EchoesRequest(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/users/{id}")).
+			WillReturnTemplate(200, `{"id":"{{.PathParams.id}}","name":"{{.JSON.name}}","q":"{{.Query.Get "q"}}"}`).
+			WithResponseHeader("X-Correlation-Id", `{{.Headers.Get "X-Correlation-Id"}}`),
+	)
+
+	req, _ := http.NewRequest("POST", server.URL()+"/users/7?q=search", strings.NewReader(`{"name":"wax"}`))
+	req.Header.Set("X-Correlation-Id", "abc")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", res.Header.Get("X-Correlation-Id"))
+
+	raw, err := mockhttp.ToResponse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"7","name":"wax","q":"search"}`, raw.Body())
+}
+
+func TestStubTemplate_Helpers(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/orders")).
+			WillReturnTemplate(201, `{{seq "orders"}} {{randomID}} {{now "2006"}} {{timestamp}}`),
+	)
+
+	first := strings.Fields(postBody(t, server.URL()+"/orders"))
+	second := strings.Fields(postBody(t, server.URL()+"/orders"))
+
+	assert.Equal(t, "1", first[0])
+	assert.Equal(t, "2", second[0])
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), first[1])
+	assert.NotEqual(t, first[1], second[1])
+	assert.Len(t, first[2], 4)
+	assert.Regexp(t, regexp.MustCompile(`^\d+$`), first[3])
+
+	server.Reset()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/orders")).
+			WillReturnTemplate(201, `{{seq "orders"}}`),
+	)
+	assert.Equal(t, "1", postBody(t, server.URL()+"/orders"))
+}
+
+func TestStubTemplate_Error(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/")).
+			WillReturnTemplate(200, `{{.Nope`),
+	)
+
+	res, err := http.Get(server.URL())
+	assert.Nil(t, err)
+	result, err := mockhttp.ToJSONResponse[mockhttp.ServerError](res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 500, result.Status())
+	assert.Equal(t, "unable to render stub template", result.Val.DebugMessage)
+}
+
+func postBody(t *testing.T, url string) string {
+	res, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+	raw, err := mockhttp.ToResponse(res)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but found an error: %v", err)
+	}
+	return raw.Body()
+}
+
+func TestStubTemplate_NoBody(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("GET", "/users/{id}")).
+			WillReturnTemplate(200, `{"id":"{{.PathParams.id}}","name":"{{field .JSON "name"}}"}`),
+	)
+
+	assert.Equal(t, `{"id":"7","name":""}`, get(t, server.URL()+"/users/7"))
+}
+
+func TestStubTemplate_MissingJSONField(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/users")).
+			WillReturnTemplate(200, `{"name":"{{.JSON.name}}","email":"{{field .JSON "email"}}","city":"{{field .JSON "address" "city"}}"}`),
+	)
+
+	res, err := http.Post(server.URL()+"/users", "application/json", strings.NewReader(`{"name":"wax"}`))
+	assert.Nil(t, err)
+	raw, err := mockhttp.ToResponse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"wax","email":"","city":""}`, raw.Body())
+}
+
+func TestStubTemplate_NonObjectBody(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/users")).
+			WillReturnTemplate(200, `{"name":"{{field .JSON "name"}}","body":{{.Body}}}`),
+	)
+
+	for _, body := range []string{`[{"name":"wax"}]`, `"wax"`, `null`} {
+		res, err := http.Post(server.URL()+"/users", "application/json", strings.NewReader(body))
+		assert.Nil(t, err)
+		raw, err := mockhttp.ToResponse(res)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, raw.Status())
+		assert.Equal(t, `{"name":"","body":`+body+`}`, raw.Body())
+	}
+}
+
+func TestStubTemplate_EchoesLiteralNoValue(t *testing.T) {
+	server := mockhttp.NewStubServer()
+	defer server.Close()
+	server.Register(
+		mockhttp.NewStub(mockhttp.NewRequestPattern("POST", "/notes")).
+			WillReturnTemplate(200, `{{field .JSON "note"}}`),
+	)
+
+	res, err := http.Post(server.URL()+"/notes", "application/json", strings.NewReader(`{"note":"<no value> here"}`))
+	assert.Nil(t, err)
+	raw, err := mockhttp.ToResponse(res)
+	assert.Nil(t, err)
+	assert.Equal(t, "<no value> here", raw.Body())
+}
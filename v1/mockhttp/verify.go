@@ -0,0 +1,206 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxNearMisses is the number of closest requests listed when a verification fails
+const maxNearMisses = 3
+
+// Verification counts the recorded requests matching a pattern
+type Verification struct {
+	pattern *RequestPattern
+	calls   []RecordedRequest
+}
+
+// Verify starts a verification of the requests received so far
+func (s *StubHandler) Verify(p *RequestPattern) *Verification {
+	return &Verification{
+		pattern: p,
+		calls:   s.Requests(),
+	}
+}
+
+// Times returns an error unless exactly n requests matched
+func (v *Verification) Times(n int) error {
+	count := v.count()
+	if count != n {
+		return v.failure(count, fmt.Sprintf("expected %s to be called %d time(s), but it was called %d time(s)", v.pattern, n, count))
+	}
+	return nil
+}
+
+func (v *Verification) Once() error {
+	return v.Times(1)
+}
+
+func (v *Verification) Never() error {
+	return v.Times(0)
+}
+
+// AtLeast returns an error unless n or more requests matched
+func (v *Verification) AtLeast(n int) error {
+	count := v.count()
+	if count < n {
+		return v.failure(count, fmt.Sprintf("expected %s to be called at least %d time(s), but it was called %d time(s)", v.pattern, n, count))
+	}
+	return nil
+}
+
+func (v *Verification) count() int {
+	count := 0
+	for i := range v.calls {
+		if v.pattern.Matches(&v.calls[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// failure appends the closest requests to msg when nothing matched
+func (v *Verification) failure(count int, msg string) error {
+	if count > 0 {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s%s", msg, nearMisses(v.pattern, v.calls))
+}
+
+// VerifyInOrder returns an error unless each pattern matched a request
+// received after the request matched by the pattern before it
+func (s *StubHandler) VerifyInOrder(patterns ...*RequestPattern) error {
+	calls := s.Requests()
+	next := 0
+	for i, p := range patterns {
+		found := false
+		for ; next < len(calls); next++ {
+			if p.Matches(&calls[next]) {
+				found = true
+				next++
+				break
+			}
+		}
+		if !found {
+			msg := fmt.Sprintf("expected %s to be called", p)
+			if i > 0 {
+				msg += fmt.Sprintf(" after %s", patterns[i-1])
+			}
+			return fmt.Errorf("%s, but it was not%s", msg, nearMisses(p, calls))
+		}
+	}
+	return nil
+}
+
+// nearMisses describes the requests that came closest to matching the pattern
+func nearMisses(p *RequestPattern, calls []RecordedRequest) string {
+	if len(calls) == 0 {
+		return "\nno requests were received"
+	}
+
+	type miss struct {
+		call    *RecordedRequest
+		reasons []string
+	}
+	misses := make([]miss, 0, len(calls))
+	for i := range calls {
+		misses = append(misses, miss{call: &calls[i], reasons: p.mismatches(&calls[i])})
+	}
+	sort.SliceStable(misses, func(i, j int) bool {
+		return len(misses[i].reasons) < len(misses[j].reasons)
+	})
+	if len(misses) > maxNearMisses {
+		misses = misses[:maxNearMisses]
+	}
+
+	var b strings.Builder
+	b.WriteString("\nclosest requests:")
+	for _, m := range misses {
+		fmt.Fprintf(&b, "\n  %s", m.call)
+		for _, reason := range m.reasons {
+			fmt.Fprintf(&b, "\n    - %s", reason)
+		}
+	}
+	return b.String()
+}
+
+// diffJSONBody compares a request body to the expected JSON value field by field
+func diffJSONBody(expected interface{}, body string) []string {
+	var want interface{}
+	data, err := json.Marshal(expected)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to marshal expected body: %v", err)}
+	}
+	if err := json.Unmarshal(data, &want); err != nil {
+		return []string{fmt.Sprintf("unable to unmarshal expected body: %v", err)}
+	}
+
+	var got interface{}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		return []string{fmt.Sprintf("expected a JSON body, but got %q", body)}
+	}
+	return diffJSON("body", want, got)
+}
+
+func diffJSON(path string, expected, result interface{}) []string {
+	switch want := expected.(type) {
+	case map[string]interface{}:
+		got, ok := result.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(want)+len(got))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		for key := range got {
+			if _, ok := want[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		var ret []string
+		for _, key := range keys {
+			w, inWant := want[key]
+			g, inGot := got[key]
+			switch {
+			case !inGot:
+				ret = append(ret, fmt.Sprintf("%s.%s: expected %s, but it was missing", path, key, toJSON(w)))
+			case !inWant:
+				ret = append(ret, fmt.Sprintf("%s.%s: unexpected value %s", path, key, toJSON(g)))
+			default:
+				ret = append(ret, diffJSON(path+"."+key, w, g)...)
+			}
+		}
+		return ret
+	case []interface{}:
+		got, ok := result.([]interface{})
+		if !ok {
+			break
+		}
+		var ret []string
+		if len(want) != len(got) {
+			ret = append(ret, fmt.Sprintf("%s: expected %d element(s), but got %d", path, len(want), len(got)))
+		}
+		for i := 0; i < len(want) && i < len(got); i++ {
+			ret = append(ret, diffJSON(fmt.Sprintf("%s[%d]", path, i), want[i], got[i])...)
+		}
+		return ret
+	}
+	if !reflect.DeepEqual(expected, result) {
+		return []string{fmt.Sprintf("%s: expected %s, but got %s", path, toJSON(expected), toJSON(result))}
+	}
+	return nil
+}
+
+func toJSON(val interface{}) string {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
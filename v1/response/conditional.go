@@ -0,0 +1,104 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Validators identify the current version of a resource for conditional requests.
+// Either field may be left empty
+type Validators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// ETag computes a strong entity tag from a representation, or a weak one
+func ETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// CheckConditional sets the ETag and Last-Modified headers and evaluates the
+// request's preconditions in the order RFC 9110 gives. It sends a 304 or 412
+// and returns true when the handler should stop, or returns false if the
+// handler should send the resource as usual
+func CheckConditional(w http.ResponseWriter, r *http.Request, v Validators) bool {
+	if v.ETag != "" {
+		w.Header().Set("ETag", v.ETag)
+	}
+	lastModified := v.LastModified.UTC().Truncate(time.Second)
+	if !v.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !matchETag(ifMatch, v.ETag, false) {
+			Error(w, http.StatusPreconditionFailed, "If-Match does not match the current ETag", nil)
+			return true
+		}
+	} else if since, ok := headerTime(r, "If-Unmodified-Since"); ok && !v.LastModified.IsZero() {
+		if lastModified.After(since) {
+			Error(w, http.StatusPreconditionFailed, "resource was modified after If-Unmodified-Since", nil)
+			return true
+		}
+	}
+
+	safe := r.Method == http.MethodGet || r.Method == http.MethodHead
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if matchETag(ifNoneMatch, v.ETag, true) {
+			if safe {
+				w.WriteHeader(http.StatusNotModified)
+			} else {
+				Error(w, http.StatusPreconditionFailed, "If-None-Match matches the current ETag", nil)
+			}
+			return true
+		}
+	} else if since, ok := headerTime(r, "If-Modified-Since"); ok && safe && !v.LastModified.IsZero() {
+		if !lastModified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// matchETag reports whether a list of entity tags from If-Match or If-None-Match
+// contains etag. If-None-Match uses the weak comparison, If-Match the strong one.
+// "*" matches any current representation, even one without an ETag
+func matchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+			continue
+		}
+		if !strings.HasPrefix(candidate, "W/") && candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func headerTime(r *http.Request, key string) (time.Time, bool) {
+	val := r.Header.Get(key)
+	if val == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(val)
+	return t, err == nil
+}
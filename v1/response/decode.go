@@ -0,0 +1,130 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the request body size limit used by DecodeJSON when none is given
+const DefaultMaxBodyBytes = 1 << 20
+
+// DecodeOptions configures DecodeJSON. A nil *DecodeOptions uses the defaults
+type DecodeOptions struct {
+	// MaxBytes limits the size of the body, defaulting to DefaultMaxBodyBytes
+	MaxBytes int64
+	// AllowUnknownFields accepts fields that aren't in the target type
+	AllowUnknownFields bool
+	// RequireContentType rejects requests without a JSON Content-Type,
+	// instead of only those with a different one
+	RequireContentType bool
+}
+
+// FieldError describes a problem with one field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors is returned by Validator implementations to report every
+// invalid field at once. Error responses list them under "fields"
+type FieldErrors []FieldError
+
+func (f FieldErrors) Error() string {
+	msgs := make([]string, len(f))
+	for i, fe := range f {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validator is implemented by request types that check their own fields after decoding
+type Validator interface {
+	Validate() error
+}
+
+// DecodeJSON decodes the request body into a T and validates it if T implements Validator.
+// When the body can't be used, an error response is sent and the error is returned,
+// so handlers only need to return:
+//
+//	415 when the Content-Type isn't JSON
+//	413 when the body is larger than the limit
+//	400 when the body is malformed, has unknown fields or fails validation
+func DecodeJSON[T any](w http.ResponseWriter, r *http.Request, opts *DecodeOptions) (T, error) {
+	var val T
+	if opts == nil {
+		opts = &DecodeOptions{}
+	}
+
+	if err := checkContentType(r, opts.RequireContentType); err != nil {
+		Error(w, http.StatusUnsupportedMediaType, "expected a JSON request body", err)
+		return val, err
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	if !opts.AllowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&val); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", maxBytes), err)
+			return val, err
+		}
+		err = decodeError(err)
+		Error(w, http.StatusBadRequest, "invalid request body", err)
+		return val, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		err = errors.New("request body must contain a single JSON value")
+		Error(w, http.StatusBadRequest, "invalid request body", err)
+		return val, err
+	}
+
+	if v, ok := any(&val).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			Error(w, http.StatusBadRequest, "invalid request body", err)
+			return val, err
+		}
+	}
+	return val, nil
+}
+
+func checkContentType(r *http.Request, required bool) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" && !required {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid Content-Type %q: %w", contentType, err)
+	}
+	if mediaType != JSONContentType && !strings.HasSuffix(mediaType, "+json") {
+		return fmt.Errorf("unsupported Content-Type %q", mediaType)
+	}
+	return nil
+}
+
+// decodeError turns errors from encoding/json into FieldErrors where they concern a field
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is empty")
+	case errors.As(err, &typeErr):
+		return FieldErrors{{Field: typeErr.Field, Message: fmt.Sprintf("expected %s, but got %s", typeErr.Type, typeErr.Value)}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return FieldErrors{{Field: field, Message: "unknown field"}}
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package response
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+var activeLogger atomic.Pointer[slog.Logger]
+
+// DiscardLogHandler drops every record. Pass it to SetLogHandler to mute logging
+var DiscardLogHandler slog.Handler = discardHandler{}
+
+// SetLogHandler sets the handler used to log failures while writing responses,
+// such as a body that can't be marshaled. By default, or after passing nil,
+// failures are logged with whatever slog.Default is at the time
+func SetLogHandler(h slog.Handler) {
+	if h == nil {
+		activeLogger.Store(nil)
+		return
+	}
+	activeLogger.Store(slog.New(h))
+}
+
+func logger() *slog.Logger {
+	if l := activeLogger.Load(); l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
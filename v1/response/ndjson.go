@@ -0,0 +1,57 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NDJSONContentType is the Content-Type of newline-delimited JSON streams
+const NDJSONContentType = "application/x-ndjson"
+
+// NDJSON streams records as newline-delimited JSON, flushing after each one
+type NDJSON[T any] struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+func NewNDJSON[T any](w http.ResponseWriter) *NDJSON[T] {
+	return &NDJSON[T]{w: w}
+}
+
+// Write sends one record. The headers and a 200 are sent with the first record,
+// so if it can't be marshaled a 500 error response is sent instead. Later
+// marshal failures can only end the stream, so the error is returned
+func (n *NDJSON[T]) Write(record T) error {
+	res, err := json.Marshal(record)
+	if err != nil {
+		logger().Error("unable to marshal record", "error", err)
+		if !n.started {
+			n.started = true
+			Error(n.w, http.StatusInternalServerError, "unable to marshal response body", err)
+		}
+		return err
+	}
+
+	n.start()
+	if _, err := n.w.Write(append(res, '\n')); err != nil {
+		return err
+	}
+	if flusher, ok := n.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// Close sends the headers if no record was written, so an empty stream is still a 200
+func (n *NDJSON[T]) Close() {
+	n.start()
+}
+
+func (n *NDJSON[T]) start() {
+	if n.started {
+		return
+	}
+	n.started = true
+	n.w.Header().Set("Content-Type", NDJSONContentType)
+	n.w.WriteHeader(http.StatusOK)
+}
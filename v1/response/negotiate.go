@@ -0,0 +1,147 @@
+package response
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types with a built in encoder
+const (
+	XMLContentType     = "application/xml"
+	MsgPackContentType = "application/msgpack"
+	CBORContentType    = "application/cbor"
+)
+
+// EncodeFunc encodes a response body for a media type
+type EncodeFunc func(body interface{}) ([]byte, error)
+
+type encoder struct {
+	mediaType string
+	encode    EncodeFunc
+}
+
+var (
+	encodersMu sync.RWMutex
+	// encoders are in order of preference for wildcard Accept headers
+	encoders = []encoder{
+		{mediaType: JSONContentType, encode: json.Marshal},
+		{mediaType: XMLContentType, encode: xml.Marshal},
+		{mediaType: "text/xml", encode: xml.Marshal},
+		{mediaType: MsgPackContentType, encode: msgpack.Marshal},
+		{mediaType: "application/x-msgpack", encode: msgpack.Marshal},
+		{mediaType: CBORContentType, encode: cbor.Marshal},
+	}
+)
+
+// RegisterEncoder adds an encoder used by Negotiate, or replaces the encoder
+// already registered for the media type
+func RegisterEncoder(mediaType string, encode EncodeFunc) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	mediaType = strings.ToLower(mediaType)
+	for i := range encoders {
+		if encoders[i].mediaType == mediaType {
+			encoders[i].encode = encode
+			return
+		}
+	}
+	encoders = append(encoders, encoder{mediaType: mediaType, encode: encode})
+}
+
+// Sends a response with the given status, encoding the body as the media type
+// the request's Accept header prefers. JSON is used when there is no Accept header,
+// and a 406 error response is sent when no registered encoder is acceptable
+func Negotiate(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	w.Header().Add("Vary", "Accept")
+	enc, ok := negotiate(r.Header.Get("Accept"))
+	if !ok {
+		Error(w, http.StatusNotAcceptable, "no acceptable media type for "+r.Header.Get("Accept"), nil)
+		return
+	}
+
+	res, err := enc.encode(body)
+	if err != nil {
+		logger().Error("unable to encode response body", "status", status, "mediaType", enc.mediaType, "error", err)
+		Error(w, http.StatusInternalServerError, "unable to encode response body", err)
+		return
+	}
+	write(w, status, enc.mediaType, res)
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate picks the encoder with the highest quality, where the quality
+// of a media type comes from the most specific range matching it. Ties go to
+// the range listed first in the Accept header, then to the encoder registered first
+func negotiate(accept string) (encoder, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	if strings.TrimSpace(accept) == "" {
+		return encoders[0], true
+	}
+
+	ranges := parseAccept(accept)
+	var best encoder
+	bestQ, bestPos := 0.0, len(ranges)
+	for _, enc := range encoders {
+		q, pos := quality(ranges, enc.mediaType)
+		if q > bestQ || (q == bestQ && q > 0 && pos < bestPos) {
+			best, bestQ, bestPos = enc, q, pos
+		}
+	}
+	return best, bestQ > 0
+}
+
+// quality returns the q value of the most specific range matching mediaType
+// and the position of that range in the Accept header
+func quality(ranges []mediaRange, mediaType string) (float64, int) {
+	q, pos, specificity := 0.0, len(ranges), 0
+	for i, mr := range ranges {
+		s := 0
+		switch {
+		case mr.mediaType == mediaType:
+			s = 3
+		case mr.mediaType == "*/*":
+			s = 1
+		case strings.HasSuffix(mr.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mr.mediaType, "*")):
+			s = 2
+		}
+		if s > specificity {
+			q, pos, specificity = mr.q, i, s
+		}
+	}
+	return q, pos
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ret []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mr := mediaRange{
+			mediaType: strings.ToLower(strings.TrimSpace(params[0])),
+			q:         1,
+		}
+		for _, param := range params[1:] {
+			key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(val, 64); err == nil {
+					mr.q = q
+				}
+			}
+		}
+		if mr.mediaType != "" {
+			ret = append(ret, mr)
+		}
+	}
+	return ret
+}
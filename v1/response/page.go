@@ -0,0 +1,75 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query params used in the Link headers written by WritePage
+const (
+	CursorParam = "cursor"
+	PageParam   = "page"
+	LimitParam  = "limit"
+)
+
+// Page is the envelope for list responses. Use Next and Prev for cursor
+// pagination, or Page, Limit and Total for numbered pages
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Page  int    `json:"page,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// Sends a 200 response with the page as JSON and an RFC 8288 Link header
+// pointing at the neighbouring pages of the request's URL
+func WritePage[T any](w http.ResponseWriter, r *http.Request, page Page[T]) {
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if link := pageLinks(r.URL, page.Next, page.Prev, page.Page, page.Limit, page.Total); link != "" {
+		w.Header().Set("Link", link)
+	}
+	JSON(w, http.StatusOK, page)
+}
+
+func pageLinks(u *url.URL, next, prev string, page, limit, total int) string {
+	var links []string
+	add := func(rel string, params map[string]string) {
+		q := u.Query()
+		for key, val := range params {
+			q.Set(key, val)
+		}
+		if limit > 0 {
+			q.Set(LimitParam, strconv.Itoa(limit))
+		}
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, q.Encode(), rel))
+	}
+
+	if next != "" {
+		add("next", map[string]string{CursorParam: next})
+	}
+	if prev != "" {
+		add("prev", map[string]string{CursorParam: prev})
+	}
+	if page > 0 && limit > 0 {
+		last := (total + limit - 1) / limit
+		if last < 1 {
+			last = 1
+		}
+		if next == "" && page < last {
+			add("next", map[string]string{PageParam: strconv.Itoa(page + 1)})
+		}
+		if prev == "" && page > 1 {
+			add("prev", map[string]string{PageParam: strconv.Itoa(page - 1)})
+		}
+		add("first", map[string]string{PageParam: "1"})
+		add("last", map[string]string{PageParam: strconv.Itoa(last)})
+	}
+	return strings.Join(links, ", ")
+}
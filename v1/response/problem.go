@@ -0,0 +1,72 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 9457 (formerly RFC 7807) problem details object.
+// Extensions are written as top level members alongside the standard ones
+type ProblemDetails struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, val := range p.Extensions {
+		body[key] = val
+	}
+	if p.Type != "" {
+		body["type"] = p.Type
+	}
+	if p.Title != "" {
+		body["title"] = p.Title
+	}
+	if p.Status != 0 {
+		body["status"] = p.Status
+	}
+	if p.Detail != "" {
+		body["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		body["instance"] = p.Instance
+	}
+	return json.Marshal(body)
+}
+
+// Sends an application/problem+json response
+// Status defaults to 500, type to about:blank and title to the standard text for the status
+func Problem(w http.ResponseWriter, p ProblemDetails) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	res, err := json.Marshal(p)
+	if err != nil {
+		logger().Error("unable to marshal problem details", "status", p.Status, "error", err)
+		// without extensions the standard members always marshal
+		res, _ = json.Marshal(ProblemDetails{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: "unable to marshal problem details",
+		})
+		write(w, http.StatusInternalServerError, ProblemContentType, res)
+		return
+	}
+	write(w, p.Status, ProblemContentType, res)
+}
@@ -2,10 +2,13 @@ package response
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 )
 
+// JSONContentType is the Content-Type set on JSON responses
+const JSONContentType = "application/json"
+
 // Sends a standard 200 response with a generic body
 func Success(w http.ResponseWriter) {
 	body := map[string]string{
@@ -15,49 +18,106 @@ func Success(w http.ResponseWriter) {
 }
 
 // Sends a 200 response with JSON representation of provided body
+// If the body can't be marshaled a 500 error response is sent instead
 func SuccessWithBody(w http.ResponseWriter, body interface{}) {
-	res, err := json.Marshal(body)
-	if err != nil {
-		fmt.Println(fmt.Sprintf("unexpected error encountered marshaling json: %v", err))
+	JSON(w, http.StatusOK, body)
+}
+
+// Sends a response with the given status and JSON representation of provided body
+// If the body can't be marshaled a 500 error response is sent instead
+func JSON(w http.ResponseWriter, status int, body interface{}) {
+	writeJSON(w, status, JSONContentType, body)
+}
+
+// Sends a 201 response with a Location header pointing at the new resource
+// An empty location is omitted, and a nil body sends no body
+func Created(w http.ResponseWriter, location string, body interface{}) {
+	if body == nil {
+		if location != "" {
+			w.Header().Set("Location", location)
+		}
+		w.WriteHeader(http.StatusCreated)
 		return
 	}
+	res, ok := marshal(w, http.StatusCreated, body)
+	if !ok {
+		return
+	}
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	write(w, http.StatusCreated, JSONContentType, res)
+}
+
+// Sends a 202 response for work that will finish later
+// A nil body sends no body
+func Accepted(w http.ResponseWriter, body interface{}) {
+	if body == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	JSON(w, http.StatusAccepted, body)
+}
 
-	w.Write(res)
+// Sends a 204 response with no body
+func NoContent(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // Sends an error response without notifying new relic
+// The error code registered for the status with RegisterStatus is included, if any
 func Error(w http.ResponseWriter, status int, message string, err error) {
-	w.WriteHeader(status)
+	ErrorWithCode(w, status, getErrorCode(status), message, err)
+}
 
-	body := map[string]string{
+// Sends an error response with a machine-readable error code, overriding
+// any code registered for the status
+// If err is or wraps FieldErrors, each field is listed under "fields"
+func ErrorWithCode(w http.ResponseWriter, status int, code, message string, err error) {
+	body := map[string]interface{}{
 		"status": getErrorStatus(status),
 	}
+	if code != "" {
+		body["code"] = code
+	}
 	if message != "" {
 		body["message"] = message
 	}
 	if err != nil {
 		body["error"] = err.Error()
 	}
+	var fields FieldErrors
+	if errors.As(err, &fields) {
+		body["fields"] = fields
+	}
+	writeJSON(w, status, JSONContentType, body)
+}
 
-	res, merr := json.Marshal(body)
-	if merr != nil {
-		fmt.Println(fmt.Sprintf("unexpected error encountered marshaling json: %v", merr))
+// writeJSON marshals body before writing any headers, so that a marshal
+// failure can still be reported to the client as a well-formed 500
+func writeJSON(w http.ResponseWriter, status int, contentType string, body interface{}) {
+	res, ok := marshal(w, status, body)
+	if !ok {
 		return
 	}
-	w.Write(res)
-}
-
-func getErrorStatus(status int) string {
-	switch status {
-	case 400:
-		return "bad request"
-	case 401:
-		return "unauthorized"
-	case 403:
-		return "forbidden"
-	case 404:
-		return "not found"
-	default:
-		return "internal error"
+	write(w, status, contentType, res)
+}
+
+// marshal sends a 500 error response and returns false if body can't be marshaled
+func marshal(w http.ResponseWriter, status int, body interface{}) ([]byte, bool) {
+	res, err := json.Marshal(body)
+	if err != nil {
+		logger().Error("unable to marshal response body", "status", status, "error", err)
+		Error(w, http.StatusInternalServerError, "unable to marshal response body", err)
+		return nil, false
+	}
+	return res, true
+}
+
+func write(w http.ResponseWriter, status int, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		logger().Warn("unable to write response body", "status", status, "error", err)
 	}
 }
@@ -0,0 +1,142 @@
+package response_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sachsry/mockhttp/v1/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuccessWithBody_ContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.SuccessWithBody(w, map[string]int{"id": 1})
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+}
+
+func TestError_ContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.Error(w, 404, "missing", errors.New("boom"))
+
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"error":"boom","message":"missing","status":"not found"}`, w.Body.String())
+}
+
+func TestSuccessWithBody_MarshalFailure(t *testing.T) {
+	var logs bytes.Buffer
+	response.SetLogHandler(slog.NewTextHandler(&logs, nil))
+	defer response.SetLogHandler(nil)
+
+	w := httptest.NewRecorder()
+	response.SuccessWithBody(w, map[string]interface{}{"ch": make(chan int)})
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"error":"json: unsupported type: chan int","message":"unable to marshal response body","status":"internal error"}`, w.Body.String())
+	assert.Contains(t, logs.String(), `msg="unable to marshal response body" status=200 error="json: unsupported type: chan int"`)
+}
+
+func TestSetLogHandler_NilFollowsDefault(t *testing.T) {
+	var logs bytes.Buffer
+	old := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(old)
+
+	response.SetLogHandler(response.DiscardLogHandler)
+	response.SuccessWithBody(httptest.NewRecorder(), make(chan int))
+	assert.Empty(t, logs.String())
+
+	response.SetLogHandler(nil)
+	response.SuccessWithBody(httptest.NewRecorder(), make(chan int))
+	assert.Contains(t, logs.String(), `msg="unable to marshal response body"`)
+}
+
+func TestProblem_MarshalFailure(t *testing.T) {
+	response.SetLogHandler(response.DiscardLogHandler)
+	defer response.SetLogHandler(nil)
+
+	w := httptest.NewRecorder()
+	response.Problem(w, response.ProblemDetails{
+		Status:     400,
+		Extensions: map[string]interface{}{"ch": make(chan int)},
+	})
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"detail":"unable to marshal problem details","status":500,"title":"Internal Server Error","type":"about:blank"}`, w.Body.String())
+}
+
+func TestJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.JSON(w, 409, map[string]int{"id": 1})
+
+	assert.Equal(t, 409, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+}
+
+func TestCreated(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.Created(w, "/things/1", map[string]int{"id": 1})
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "/things/1", w.Header().Get("Location"))
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	response.Created(w, "", nil)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "", w.Header().Get("Location"))
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestCreated_MarshalFailure(t *testing.T) {
+	response.SetLogHandler(response.DiscardLogHandler)
+	defer response.SetLogHandler(nil)
+
+	w := httptest.NewRecorder()
+	response.Created(w, "/things/1", make(chan int))
+
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "", w.Header().Get("Location"))
+}
+
+func TestAcceptedAndNoContent(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.Accepted(w, nil)
+	assert.Equal(t, 202, w.Code)
+	assert.Equal(t, "", w.Body.String())
+
+	w = httptest.NewRecorder()
+	response.NoContent(w)
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestDecodeJSON_RequireContentType(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+
+	_, err := response.DecodeJSON[map[string]interface{}](w, r, &response.DecodeOptions{RequireContentType: true})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 415, w.Code)
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"id":1}`))
+	r.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+	w = httptest.NewRecorder()
+
+	val, err := response.DecodeJSON[map[string]interface{}](w, r, &response.DecodeOptions{RequireContentType: true})
+
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), val["id"])
+}
@@ -0,0 +1,136 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventStreamContentType is the Content-Type of Server-Sent Events streams
+const EventStreamContentType = "text/event-stream"
+
+// Event is a single Server-Sent Event. Data that is a string or []byte is sent
+// as is, anything else is sent as JSON. Empty fields are omitted
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// SSE writes a Server-Sent Events stream
+type SSE struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	ctx     context.Context
+}
+
+// NewSSE starts an event stream on w. It sends a 500 error response and returns
+// an error if w can't flush. The stream stops accepting events once the
+// request's context is done, which happens when the client disconnects
+func NewSSE(w http.ResponseWriter, r *http.Request) (*SSE, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := errors.New("response writer does not support flushing")
+		Error(w, http.StatusInternalServerError, "unable to stream events", err)
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &SSE{w: w, flusher: flusher, ctx: r.Context()}, nil
+}
+
+// Done is closed when the client disconnects
+func (s *SSE) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send writes and flushes an event. It returns the context's error without
+// writing anything once the client has disconnected, and an error if the ID or
+// Event contains a line break, since that would end the field early
+func (s *SSE) Send(e Event) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if strings.ContainsAny(e.ID, "\r\n") {
+		return fmt.Errorf("expected an event ID without line breaks, but got %q", e.ID)
+	}
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return fmt.Errorf("expected an event type without line breaks, but got %q", e.Event)
+	}
+
+	var b strings.Builder
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	if e.Data != nil {
+		data, err := eventData(e.Data)
+		if err != nil {
+			return err
+		}
+		for _, line := range splitLines(data) {
+			fmt.Fprintf(&b, "data: %s\n", line)
+		}
+	}
+	b.WriteString("\n")
+
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Comment writes text as comment lines, which clients ignore. It's useful as a keep-alive
+func (s *SSE) Comment(text string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, line := range splitLines(text) {
+		fmt.Fprintf(&b, ": %s\n", line)
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// splitLines splits on CRLF, CR and LF, which all end a line in an event stream
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
+
+func eventData(data interface{}) (string, error) {
+	switch d := data.(type) {
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	}
+	res, err := json.Marshal(data)
+	if err != nil {
+		logger().Error("unable to marshal event data", "error", err)
+		return "", err
+	}
+	return string(res), nil
+}
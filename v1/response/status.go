@@ -0,0 +1,101 @@
+package response
+
+import (
+	"maps"
+	"net/http"
+	"sync"
+)
+
+type statusInfo struct {
+	text string
+	code string
+}
+
+var (
+	statusMu sync.RWMutex
+	statuses = map[int]statusInfo{
+		http.StatusBadRequest:                   {text: "bad request"},
+		http.StatusUnauthorized:                 {text: "unauthorized"},
+		http.StatusPaymentRequired:              {text: "payment required"},
+		http.StatusForbidden:                    {text: "forbidden"},
+		http.StatusNotFound:                     {text: "not found"},
+		http.StatusMethodNotAllowed:             {text: "method not allowed"},
+		http.StatusNotAcceptable:                {text: "not acceptable"},
+		http.StatusProxyAuthRequired:            {text: "proxy authentication required"},
+		http.StatusRequestTimeout:               {text: "request timeout"},
+		http.StatusConflict:                     {text: "conflict"},
+		http.StatusGone:                         {text: "gone"},
+		http.StatusLengthRequired:               {text: "length required"},
+		http.StatusPreconditionFailed:           {text: "precondition failed"},
+		http.StatusRequestEntityTooLarge:        {text: "request entity too large"},
+		http.StatusRequestURITooLong:            {text: "request uri too long"},
+		http.StatusUnsupportedMediaType:         {text: "unsupported media type"},
+		http.StatusRequestedRangeNotSatisfiable: {text: "requested range not satisfiable"},
+		http.StatusExpectationFailed:            {text: "expectation failed"},
+		http.StatusTeapot:                       {text: "i'm a teapot"},
+		http.StatusMisdirectedRequest:           {text: "misdirected request"},
+		http.StatusUnprocessableEntity:          {text: "unprocessable entity"},
+		http.StatusLocked:                       {text: "locked"},
+		http.StatusFailedDependency:             {text: "failed dependency"},
+		http.StatusTooEarly:                     {text: "too early"},
+		http.StatusUpgradeRequired:              {text: "upgrade required"},
+		http.StatusPreconditionRequired:         {text: "precondition required"},
+		http.StatusTooManyRequests:              {text: "too many requests"},
+		http.StatusRequestHeaderFieldsTooLarge:  {text: "request header fields too large"},
+		http.StatusUnavailableForLegalReasons:   {text: "unavailable for legal reasons"},
+
+		http.StatusInternalServerError:           {text: "internal error"},
+		http.StatusNotImplemented:                {text: "not implemented"},
+		http.StatusBadGateway:                    {text: "bad gateway"},
+		http.StatusServiceUnavailable:            {text: "service unavailable"},
+		http.StatusGatewayTimeout:                {text: "gateway timeout"},
+		http.StatusHTTPVersionNotSupported:       {text: "http version not supported"},
+		http.StatusVariantAlsoNegotiates:         {text: "variant also negotiates"},
+		http.StatusInsufficientStorage:           {text: "insufficient storage"},
+		http.StatusLoopDetected:                  {text: "loop detected"},
+		http.StatusNotExtended:                   {text: "not extended"},
+		http.StatusNetworkAuthenticationRequired: {text: "network authentication required"},
+	}
+	defaultStatuses = maps.Clone(statuses)
+)
+
+// RegisterStatus overrides the status text sent by Error for a status code,
+// and sets a machine-readable error code that is sent alongside it.
+// An empty text keeps the current text, and an empty code sends no code
+func RegisterStatus(status int, text, code string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	info := statuses[status]
+	if text != "" {
+		info.text = text
+	}
+	info.code = code
+	statuses[status] = info
+}
+
+// UnregisterStatus undoes RegisterStatus for a status code, restoring its
+// built in text and removing its code
+func UnregisterStatus(status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if info, ok := defaultStatuses[status]; ok {
+		statuses[status] = info
+		return
+	}
+	delete(statuses, status)
+}
+
+func getErrorStatus(status int) string {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	if info, ok := statuses[status]; ok && info.text != "" {
+		return info.text
+	}
+	return "internal error"
+}
+
+func getErrorCode(status int) string {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return statuses[status].code
+}